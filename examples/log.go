@@ -1,19 +1,52 @@
 package main
 
-import "github.com/zngw/golib/log"
+import (
+	"context"
+
+	"github.com/zngw/golib/log"
+)
 
 func main() {
 	// 无初始化默认终端输出
 
+	// 程序退出前关闭日志，写入异步队列中的日志
+	defer log.Close()
+
 	// 输出到文件日志配置
 	log.Init(log.Option{
-		LogPath:         "log/file.log", // 日志文件路径。缺省为终端输出
-		LogLevel:        "trace",        // 输出等级，缺省为trace，可选 trace、info、debug、warn、error
-		Tags:            "",             // Tag, 缺省为显示所有tag，调用输出不带tag时，不受tag标签影响
-		MaxDays:         7,              // 日志文件保留天数，仅在文件模式下生效，缺省为永久保留
-		DisableLogColor: false,          // 是否禁用日志颜色显示，仅在终端模式下生效，缺省为不禁用
-		DisableCaller:   false,          // 是否禁用显示打印所在文件及行数，缺省为不禁用
-		CallerSkip:      0,              // 打印日志文件调用层级参数，缺省为0，即当前掉用log.Trace接口所在文件行数
+		LogPath:          "log/file.log", // 日志文件路径。缺省为终端输出
+		LogLevel:         "trace",        // 输出等级，缺省为trace，可选 trace、info、debug、warn、error、panic、fatal
+		Tags:             "",             // Tag, 缺省为显示所有tag，调用输出不带tag时，不受tag标签影响。可为tag单独设置等级，如 "db:debug,net:warn,cache"
+		MaxDays:          7,              // 日志文件保留天数，仅在文件模式下生效，缺省为永久保留
+		RotateMode:       "daily",        // 日志文件轮转模式，缺省为daily，可选 daily、hourly、interval、size、daily_size、none
+		RotateInterval:   0,              // 轮转间隔，interval模式下生效，如 15 * time.Minute，缺省为1小时
+		MaxSize:          100,            // 单个日志文件最大尺寸(MB)，按大小轮转时生效，缺省为100
+		MaxBackups:       0,              // 最多保留的备份文件个数，缺省为不限制
+		MaxTotalSize:     0,              // 日志文件及所有备份的总大小上限(MB)，超出时从最旧的备份开始删除，缺省为不限制
+		LevelFiles:       "",             // 按等级分出的日志文件，如 "warn:error" 时warn及以上的日志同时写入 log/file.error.log，各文件独立轮转和清理
+		MaxAge:           0,              // 备份文件保留时长，如 36 * time.Hour，配置后优先于MaxDays
+		ReopenSignal:     false,          // 收到SIGHUP或SIGUSR1信号时重新打开日志文件，配合logrotate使用，缺省为不监听
+		Compress:         "",             // 备份文件压缩算法，缺省为不压缩，可选 gzip，zstd需先通过log.RegisterCompressor注册
+		DisableLogColor:  false,          // 是否禁用日志颜色显示，仅在终端模式下生效，缺省为不禁用
+		DisableCaller:    false,          // 是否禁用显示打印所在文件及行数，缺省为不禁用
+		CallerSkip:       0,              // 打印日志文件调用层级参数，缺省为0，即当前掉用log.Trace接口所在文件行数
+		CallerMode:       "",             // 调用位置格式，缺省为short文件名，可选 short、relative相对模块根目录的路径、full完整路径、function文件名及函数名
+		StackLevel:       "",             // 日志等级达到该等级时附带调用栈，如error，缺省为不记录
+		Encoding:         "text",         // 日志编码格式，缺省为text，可选 text、json
+		Layout:           "",             // 文本格式模板，缺省为 "{time} {level} {caller} {tag} {msg}"，还可使用{fields}
+		TimeFormat:       "",             // 时间格式，缺省为 "2006-01-02 15:04:05.000"，可为Go时间格式或 rfc3339、rfc3339ms、unix、unixms
+		TimeZone:         "",             // 时区，缺省为local，可选 local、utc或时区名称如 Asia/Shanghai
+		LevelFormat:      "",             // 等级名称格式，缺省为short如[I]，可选 short、full如[INFO]
+		Async:            false,          // 是否异步写日志，缺省为同步写，异步时程序退出前需调用 log.Close()
+		AsyncQueueSize:   8192,           // 异步队列容量(日志条数)，缺省为8192
+		AsyncOverflow:    "block",        // 异步队列满时的处理策略，缺省为block，可选 block、drop、drop_low
+		SampleInitial:    0,              // 重复日志采样，每个周期内相同等级、tag、模板的日志前N条全部输出，缺省为0不采样
+		SampleThereafter: 0,              // 超出前N条后每M条输出1条，为0时全部丢弃
+		SampleInterval:   0,              // 采样周期，缺省为1秒
+		CollapseRepeated: false,          // 是否合并连续重复的日志，输出为 "last message repeated N times"
+		CollapseTimeout:  0,              // 重复日志最长缓存时间，缺省为30秒
+		RedactFields:     "",             // 需要脱敏的字段名，如 "password,token,secret"，字段名包含其中之一时值替换为******
+		RedactRules:      "",             // 日志内容和字段值的脱敏规则，可选 mobile、idcard、bankcard、email，如手机号输出为138****1234
 	})
 
 	// format 传入一个字符串
@@ -30,6 +63,39 @@ func main() {
 	log.Error("sys", "带tag格式化输出 %10s", "符字串")
 	log.Log(log.LevelInfo, 0, "net", "带tag格式化输出 %10s， %d", "符字串", 55)
 
+	// 结构化字段输出，key, value 交替排列
+	log.Infow("用户登录", "uid", 10086, "cost", "35ms")
+
+	// With 派生附带字段的子日志对象，子对象输出的每条日志都会带上这些字段
+	reqLog := log.With("request_id", "9f8e7d")
+	reqLog.Info("处理请求 %s", "/api/user")
+	reqLog.Warnw("请求耗时过长", "cost", "1.2s")
+
+	// 带context的日志，附加context中的trace_id、request_id等字段，可用 log.RegisterContextExtractor 注册自定义提取方法
+	ctx := log.ContextWithTraceID(context.Background(), "4bf92f3577b34da6")
+	ctx = log.ContextWithRequestID(ctx, "9f8e7d")
+	log.InfoCtx(ctx, "处理请求 %s", "/api/order")
+
+	// 标准库 log/slog 接口，与全局日志对象共享输出配置
+	log.Slog().Info("slog输出", "uid", 10086)
+
+	// 注册退出钩子，log.Fatal 输出日志后先执行钩子，再关闭输出并退出程序
+	log.RegisterExitHook(func() {
+		log.Info("sys", "服务退出，清理资源")
+	})
+
+	// 日志钩子，如将Error日志推送到告警队列，AddAsyncHook 在后台协程中执行钩子
+	_ = log.AddAsyncHook(log.NewHook(func(e *log.Entry) error {
+		// pushAlert(e.Time, e.Tag, e.Caller, e.Message)
+		return nil
+	}, log.LevelError, log.LevelFatal))
+
+	// 运行时修改日志等级和tag，不会重新打开输出
+	log.SetLevel(log.LevelDebug)
+	_ = log.SetTags("db:trace,net")
+	// 也可以将 log.LevelHandler() 挂载到管理端口，通过HTTP GET查看、PUT修改，如
+	// http.Handle("/debug/log/level", log.LevelHandler())
+
 	// 可以创建多个独立的日志对象单独输出
 	errlog := log.New(log.Option{
 		LogPath:  "log/err.log",
@@ -42,4 +108,29 @@ func main() {
 		LogPath: "console",
 	})
 	mylog.Trace("net", "mylog 日志输出")
+
+	// 同一个日志对象同时输出到多个目标，每个输出有独立的等级、Tag过滤、颜色和编码格式
+	multiLog := log.New(log.Option{
+		Outputs: []log.OutputOption{
+			{LogPath: "console", LogLevel: "debug"},
+			{LogPath: "log/app.log", LogLevel: "info"},
+			{LogPath: "log/error.log", LogLevel: "error", Encoding: "json"},
+		},
+	})
+	defer multiLog.Close()
+	multiLog.Error("sys", "同时输出到终端、app.log和error.log")
+
+	// 从环境变量或配置文件加载配置，变量名为前缀加配置项名称的大写，如 APP_LOG_LEVEL、APP_LOG_PATH、APP_LOG_MAX_DAYS，
+	// 配置文件支持 .json、.yaml、.toml，配置项名称与Option的json标签相同，时长使用 "15m" 格式。
+	// 与Init不同，无法识别的配置项和取值会返回错误
+	if opt, err := log.LoadOptionFromEnv("APP_LOG"); err == nil {
+		envLog := log.New(opt)
+		envLog.Info("sys", "使用环境变量中的日志配置")
+	} else {
+		log.Error("sys", "日志配置错误: %v", err)
+	}
+	// opt, err := log.LoadOptionFromFile("config/log.yaml")
+
+	// 加载配置文件并在文件修改后自动重新加载，只更新变化的配置，未变化的输出不会重新打开
+	// stop, err := log.WatchConfig("config/log.yaml", 2*time.Second)
 }
@@ -0,0 +1,122 @@
+package log
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+type callerMode string
+
+const (
+	callerModeShort    callerMode = "short"    // 文件名，如 handler.go:12
+	callerModeRelative callerMode = "relative" // 相对模块根目录的路径，如 internal/api/handler.go:12
+	callerModeFull     callerMode = "full"     // 完整路径，如 /home/app/internal/api/handler.go:12
+	callerModeFunction callerMode = "function" // 文件名及函数名，如 handler.go:12 api.(*Server).Handle
+)
+
+func parseCallerMode(text string) (callerMode, error) {
+	switch mode := callerMode(strings.ToLower(text)); mode {
+	case "":
+		return callerModeShort, nil
+	case callerModeShort, callerModeRelative, callerModeFull, callerModeFunction:
+		return mode, nil
+	default:
+		return callerModeShort, fmt.Errorf("unrecognized caller mode: %q", text)
+	}
+}
+
+// mainModule 主模块路径及main包的导入路径，用于计算相对模块根目录的路径
+var mainModule, mainPackage = func() (string, string) {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "", ""
+	}
+	return bi.Main.Path, bi.Path
+}()
+
+func getCaller(skip int, mode callerMode) string {
+	pcs := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pcs) == 0 {
+		return "???:0"
+	}
+	frame, _ := runtime.CallersFrames(pcs).Next()
+	return formatCaller(frame, mode)
+}
+
+func callerFromPC(pc uintptr, mode callerMode) string {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return formatCaller(frame, mode)
+}
+
+func formatCaller(frame runtime.Frame, mode callerMode) string {
+	if frame.File == "" {
+		return "???:0"
+	}
+
+	line := strconv.Itoa(frame.Line)
+	switch mode {
+	case callerModeFull:
+		return frame.File + ":" + line
+	case callerModeRelative:
+		return relativePath(frame) + ":" + line
+	case callerModeFunction:
+		_, file := path.Split(frame.File)
+		return file + ":" + line + " " + shortFunction(frame.Function)
+	default:
+		_, file := path.Split(frame.File)
+		return file + ":" + line
+	}
+}
+
+// packagePath 返回函数所在包的导入路径，如 github.com/a/b.(*T).M 返回 github.com/a/b。
+// 符号名中导入路径最后一级的 . 被转义为 %2e，如 gopkg.in/yaml%2ev3.Marshal，
+// 因此最后一个 / 之后的第一个 . 即为包路径的结尾，截取后再还原转义的字符
+func packagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	pkg := function
+	if dot := strings.IndexByte(function[slash+1:], '.'); dot >= 0 {
+		pkg = function[:slash+1+dot]
+	}
+	return unescapeSymbol(pkg)
+}
+
+// shortFunction 去掉函数名中包路径的目录部分，如 github.com/a/b.(*T).M 返回 b.(*T).M
+func shortFunction(function string) string {
+	return unescapeSymbol(function[strings.LastIndexByte(function, '/')+1:])
+}
+
+// unescapeSymbol 还原符号名中转义的字符，如 yaml%2ev3 还原为 yaml.v3
+func unescapeSymbol(s string) string {
+	if strings.IndexByte(s, '%') < 0 {
+		return s
+	}
+	if u, err := url.PathUnescape(s); err == nil {
+		return u
+	}
+	return s
+}
+
+// relativePath 返回文件相对模块根目录的路径，非主模块的文件返回 包导入路径/文件名
+func relativePath(frame runtime.Frame) string {
+	_, file := path.Split(frame.File)
+	pkg := packagePath(frame.Function)
+	if pkg == "main" && mainPackage != "" {
+		pkg = mainPackage
+	}
+
+	switch {
+	case mainModule != "" && pkg == mainModule:
+		return file
+	case mainModule != "" && strings.HasPrefix(pkg, mainModule+"/"):
+		return pkg[len(mainModule)+1:] + "/" + file
+	case pkg == "" || pkg == "main":
+		return file
+	default:
+		return pkg + "/" + file
+	}
+}
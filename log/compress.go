@@ -0,0 +1,114 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// compressor 备份文件压缩算法
+type compressor struct {
+	ext       string                                    // 压缩文件扩展名，如 .gz
+	newWriter func(w io.Writer) (io.WriteCloser, error) // 创建压缩写入器
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]compressor{
+		"gzip": {
+			ext: ".gz",
+			newWriter: func(w io.Writer) (io.WriteCloser, error) {
+				return gzip.NewWriter(w), nil
+			},
+		},
+		// zstd 不引入第三方依赖，需要通过 RegisterCompressor 注册实现后使用，
+		// 未注册时仍能识别 .zst 备份文件用于清理
+		"zstd": {ext: ".zst"},
+	}
+)
+
+// RegisterCompressor 注册备份文件压缩算法，注册后可以在 Option.Compress 中使用name选择。
+// 例如使用 github.com/klauspost/compress/zstd 注册zstd：
+//
+//	log.RegisterCompressor("zstd", ".zst", func(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	})
+func RegisterCompressor(name, ext string, newWriter func(w io.Writer) (io.WriteCloser, error)) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[strings.ToLower(name)] = compressor{ext: ext, newWriter: newWriter}
+}
+
+func getCompressor(name string) (compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	c, ok := compressors[strings.ToLower(name)]
+	if !ok {
+		return compressor{}, fmt.Errorf("unrecognized compress: %q", name)
+	}
+	if c.newWriter == nil {
+		return compressor{}, fmt.Errorf("compressor %q is not registered, see RegisterCompressor", name)
+	}
+	return c, nil
+}
+
+// trimCompressExt 去掉文件名中已知的压缩扩展名
+func trimCompressExt(filename string) (name string, compressed bool) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	for _, c := range compressors {
+		if c.ext != "" && strings.HasSuffix(filename, c.ext) {
+			return filename[:len(filename)-len(c.ext)], true
+		}
+	}
+	return filename, false
+}
+
+// compressFile 将src压缩为 src+ext，成功后删除src
+func compressFile(src string, c compressor) (err error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("open logfile error: %s", err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("get stat of logfile error: %s", err)
+	}
+
+	dst := src + c.ext
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode())
+	if err != nil {
+		return fmt.Errorf("open compressed logfile error: %s", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(dst)
+		}
+	}()
+
+	zw, err := c.newWriter(out)
+	if err != nil {
+		_ = out.Close()
+		return fmt.Errorf("create compressor error: %s", err)
+	}
+	if _, err = io.Copy(zw, f); err != nil {
+		_ = zw.Close()
+		_ = out.Close()
+		return fmt.Errorf("compress logfile error: %s", err)
+	}
+	if err = zw.Close(); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("compress logfile error: %s", err)
+	}
+	if err = out.Close(); err != nil {
+		return fmt.Errorf("close compressed logfile error: %s", err)
+	}
+
+	_ = f.Close()
+	return os.Remove(src)
+}
@@ -0,0 +1,300 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 配置文件格式
+const (
+	configFormatJSON = "json"
+	configFormatYAML = "yaml"
+	configFormatTOML = "toml"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadOptionFromEnv 从环境变量读取日志配置，变量名为 prefix 加下划线加配置项名称的大写，
+// 配置项名称与Option的json标签相同。如prefix为APP_LOG时读取 APP_LOG_LEVEL、APP_LOG_PATH、
+// APP_LOG_TAGS、APP_LOG_MAX_DAYS、APP_LOG_ROTATE_INTERVAL=15m 等，APP_LOG_OUTPUTS 为JSON数组。
+// 未设置的变量保持零值，取值无法识别时返回错误
+func LoadOptionFromEnv(prefix string) (Option, error) {
+	if prefix != "" && !strings.HasSuffix(prefix, "_") {
+		prefix += "_"
+	}
+
+	m := make(map[string]any)
+	t := reflect.TypeOf(Option{})
+	for i := 0; i < t.NumField(); i++ {
+		key := optionKey(t.Field(i))
+		if key == "-" {
+			continue
+		}
+		val, ok := os.LookupEnv(prefix + strings.ToUpper(key))
+		if !ok {
+			continue
+		}
+		m[key] = val
+	}
+
+	return newOption(m)
+}
+
+// LoadOptionFromFile 读取日志配置文件，按扩展名识别格式，支持 .json、.yaml、.yml、.toml
+func LoadOptionFromFile(path string) (Option, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Option{}, err
+	}
+
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	if format == "yml" {
+		format = configFormatYAML
+	}
+	opt, err := ParseOption(data, format)
+	if err != nil {
+		return opt, fmt.Errorf("%s: %w", path, err)
+	}
+	return opt, nil
+}
+
+// ParseOption 解析日志配置，format为json、yaml或toml。YAML和TOML只支持日志配置用到的语法：
+// 顶层的键值对及outputs列表，时长使用 "15m"、"1h30m" 等格式
+func ParseOption(data []byte, format string) (Option, error) {
+	var m map[string]any
+	var err error
+	switch strings.ToLower(format) {
+	case configFormatJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&m)
+	case configFormatYAML, "yml":
+		m, err = parseYAML(data)
+	case configFormatTOML:
+		m, err = parseTOML(data)
+	default:
+		return Option{}, fmt.Errorf("unrecognized config format: %q", format)
+	}
+	if err != nil {
+		return Option{}, err
+	}
+
+	return newOption(m)
+}
+
+// Validate 检查配置取值，返回所有无法识别的配置项。WithOptions 会忽略这些配置项并使用缺省值
+func (opt Option) Validate() error {
+	var errs []error
+	check := func(key string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	if opt.LogLevel != "" {
+		_, err := parseLevel(opt.LogLevel)
+		check("level", err)
+	}
+	_, err := parseTags(opt.Tags)
+	check("tags", err)
+	mode, err := parseRotateMode(opt.RotateMode)
+	check("rotate_mode", err)
+	if err == nil && mode == rotateFileModeNone && opt.MaxTotalSize > 0 {
+		// 不轮转时备份由logrotate等外部工具管理，不按总大小删除
+		check("max_total_size", errors.New("not supported with rotate_mode none"))
+	}
+	_, err = parseLevelFiles(opt.LevelFiles)
+	check("level_files", err)
+	if opt.Compress != "" {
+		_, err = getCompressor(opt.Compress)
+		check("compress", err)
+	}
+	_, err = parseCallerMode(opt.CallerMode)
+	check("caller_mode", err)
+	if opt.StackLevel != "" {
+		_, err = parseLevel(opt.StackLevel)
+		check("stack_level", err)
+	}
+	if _, err = newEncoder(newEncoderConfig(opt)); err != nil {
+		errs = append(errs, err) // 错误信息中已包含编码、时区或等级格式
+	}
+	_, err = parseOverflowPolicy(opt.AsyncOverflow)
+	check("async_overflow", err)
+	_, err = newRedactor(opt.RedactFields, opt.RedactRules)
+	check("redact_rules", err)
+
+	for key, n := range map[string]int64{
+		"max_days":          int64(opt.MaxDays),
+		"rotate_interval":   int64(opt.RotateInterval),
+		"max_size":          int64(opt.MaxSize),
+		"max_backups":       int64(opt.MaxBackups),
+		"max_total_size":    int64(opt.MaxTotalSize),
+		"max_age":           int64(opt.MaxAge),
+		"caller_skip":       int64(opt.CallerSkip),
+		"async_queue_size":  int64(opt.AsyncQueueSize),
+		"sample_initial":    int64(opt.SampleInitial),
+		"sample_thereafter": int64(opt.SampleThereafter),
+		"sample_interval":   int64(opt.SampleInterval),
+		"collapse_timeout":  int64(opt.CollapseTimeout),
+	} {
+		if n < 0 {
+			check(key, errors.New("must not be negative"))
+		}
+	}
+
+	for i, o := range opt.Outputs {
+		key := fmt.Sprintf("outputs[%d]", i)
+		if o.LogLevel != "" {
+			_, err = parseLevel(o.LogLevel)
+			check(key+".level", err)
+		}
+		_, err = parseTags(o.Tags)
+		check(key+".tags", err)
+		if o.Encoding != "" {
+			_, err = newEncoder(encoderConfig{Encoding: o.Encoding})
+			check(key+".encoding", err)
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errors.Join(errs...)
+}
+
+// newOption 由解析出的配置生成Option并检查取值，返回的错误包含所有类型错误和无法识别的配置项，
+// 出错时仍返回可以转换的配置，调用方可以选择忽略错误继续使用
+func newOption(m map[string]any) (Option, error) {
+	var opt Option
+	err := decodeOption(reflect.ValueOf(&opt).Elem(), m, "")
+	return opt, errors.Join(err, opt.Validate())
+}
+
+// optionKey 配置项名称，取json标签
+func optionKey(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// decodeOption 将解析出的配置写入结构体，path为错误信息中配置项的前缀
+func decodeOption(dst reflect.Value, m map[string]any, path string) error {
+	t := dst.Type()
+	fields := make(map[string]int, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := optionKey(t.Field(i)); key != "-" {
+			fields[key] = i
+		}
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	for _, k := range keys {
+		i, ok := fields[k]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s%s: unknown option", path, k))
+			continue
+		}
+		if err := decodeValue(dst.Field(i), m[k], path+k); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// decodeValue 按字段类型转换配置值，字符串可以转换为数字、布尔值和时长，环境变量的取值都是字符串
+func decodeValue(v reflect.Value, raw any, key string) error {
+	if raw == nil {
+		return nil
+	}
+
+	switch {
+	case v.Type() == durationType:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: duration must be a string such as \"15m\", got %v", key, raw)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string, got %v", key, raw)
+		}
+		v.SetString(s)
+	case v.Kind() == reflect.Int:
+		var n int64
+		var err error
+		switch x := raw.(type) {
+		case json.Number:
+			n, err = x.Int64()
+		case int64:
+			n = x
+		case string:
+			n, err = strconv.ParseInt(strings.TrimSpace(x), 10, 0)
+		default:
+			err = fmt.Errorf("expected an integer, got %v", raw)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		v.SetInt(n)
+	case v.Kind() == reflect.Bool:
+		switch x := raw.(type) {
+		case bool:
+			v.SetBool(x)
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(x))
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			v.SetBool(b)
+		default:
+			return fmt.Errorf("%s: expected a boolean, got %v", key, raw)
+		}
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Struct:
+		if s, ok := raw.(string); ok {
+			dec := json.NewDecoder(strings.NewReader(s))
+			dec.UseNumber()
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		list, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a list, got %v", key, raw)
+		}
+
+		var errs []error
+		v.Set(reflect.MakeSlice(v.Type(), len(list), len(list)))
+		for i, item := range list {
+			m, ok := item.(map[string]any)
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s[%d]: expected an object, got %v", key, i, item))
+				continue
+			}
+			errs = append(errs, decodeOption(v.Index(i), m, fmt.Sprintf("%s[%d].", key, i)))
+		}
+		return errors.Join(errs...)
+	default:
+		return fmt.Errorf("%s: unsupported option type %s", key, v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package log
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseYAML 解析日志配置用到的YAML子集：顶层的 key: value，以及由 "- key: value" 组成的对象列表，如
+//
+//	level: info
+//	outputs:
+//	  - path: logs/error.log
+//	    level: error
+//
+// 未加引号的值都作为字符串，由decodeValue按字段类型转换
+func parseYAML(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	var list []any          // 当前顶层key下的列表
+	var item map[string]any // 列表中当前的对象
+	listKey, itemIndent := "", -1
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for no := 1; scanner.Scan(); no++ {
+		line := stripComment(scanner.Text(), false)
+		text := strings.TrimSpace(line)
+		if text == "" || text == "---" {
+			continue
+		}
+		if strings.HasPrefix(line, "\t") {
+			return nil, fmt.Errorf("yaml line %d: tabs are not allowed for indentation", no)
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		rest, isItem := strings.CutPrefix(text, "-")
+		isItem = isItem && (rest == "" || rest[0] == ' ')
+
+		// 列表项可以与上级key对齐，如 "outputs:" 下一行为 "- path: a.log"
+		if indent == 0 && !(isItem && listKey != "") {
+			if isItem {
+				return nil, fmt.Errorf("yaml line %d: list item without a key", no)
+			}
+			key, val, ok := splitYAMLKey(text)
+			if !ok {
+				return nil, fmt.Errorf("yaml line %d: expected \"key: value\"", no)
+			}
+			if _, dup := root[key]; dup {
+				return nil, fmt.Errorf("yaml line %d: duplicate key %q", no, key)
+			}
+			list, item, listKey, itemIndent = nil, nil, "", -1
+			if val == "" {
+				listKey = key
+				root[key] = nil
+				continue
+			}
+			v, err := yamlScalar(val)
+			if err != nil {
+				return nil, fmt.Errorf("yaml line %d: %w", no, err)
+			}
+			root[key] = v
+			continue
+		}
+
+		if listKey == "" {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", no)
+		}
+		if isItem {
+			item = make(map[string]any)
+			list = append(list, item)
+			root[listKey] = list
+			text = strings.TrimSpace(rest)
+			itemIndent = indent + 1 + len(rest) - len(strings.TrimLeft(rest, " "))
+			if text == "" {
+				itemIndent = -1
+				continue
+			}
+		} else if item == nil || (itemIndent >= 0 && indent != itemIndent) {
+			return nil, fmt.Errorf("yaml line %d: unexpected indentation", no)
+		} else {
+			itemIndent = indent
+		}
+
+		key, val, ok := splitYAMLKey(text)
+		if !ok || val == "" {
+			return nil, fmt.Errorf("yaml line %d: expected \"key: value\"", no)
+		}
+		if _, dup := item[key]; dup {
+			return nil, fmt.Errorf("yaml line %d: duplicate key %q", no, key)
+		}
+		v, err := yamlScalar(val)
+		if err != nil {
+			return nil, fmt.Errorf("yaml line %d: %w", no, err)
+		}
+		item[key] = v
+	}
+	return root, scanner.Err()
+}
+
+// splitYAMLKey 拆分 "key: value"，value为空表示下面是缩进的子项
+func splitYAMLKey(text string) (key, val string, ok bool) {
+	if k, ok := strings.CutSuffix(text, ":"); ok && !strings.Contains(k, ": ") {
+		return strings.TrimSpace(k), "", k != ""
+	}
+	key, val, ok = strings.Cut(text, ": ")
+	key = strings.TrimSpace(key)
+	return key, strings.TrimSpace(val), ok && key != ""
+}
+
+func yamlScalar(val string) (any, error) {
+	switch {
+	case val == "~" || val == "null":
+		return nil, nil
+	case strings.HasPrefix(val, `"`):
+		return strconv.Unquote(val)
+	case strings.HasPrefix(val, "'"):
+		if len(val) < 2 || !strings.HasSuffix(val, "'") {
+			return nil, fmt.Errorf("unterminated string %s", val)
+		}
+		return strings.ReplaceAll(val[1:len(val)-1], "''", "'"), nil
+	case strings.HasPrefix(val, "[") || strings.HasPrefix(val, "{"):
+		return nil, fmt.Errorf("flow style %s is not supported", val)
+	}
+	return val, nil
+}
+
+// parseTOML 解析日志配置用到的TOML子集：顶层的 key = value，以及 [[outputs]] 表数组。
+// 值支持字符串、整数和布尔值
+func parseTOML(data []byte) (map[string]any, error) {
+	root := make(map[string]any)
+	table := root
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for no := 1; scanner.Scan(); no++ {
+		text := strings.TrimSpace(stripComment(scanner.Text(), true))
+		if text == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "[") {
+			name, ok := strings.CutPrefix(text, "[[")
+			name, ok2 := strings.CutSuffix(name, "]]")
+			if !ok || !ok2 {
+				return nil, fmt.Errorf("toml line %d: only arrays of tables such as [[outputs]] are supported", no)
+			}
+			name = strings.TrimSpace(name)
+			list, _ := root[name].([]any)
+			if _, exists := root[name]; exists && list == nil {
+				return nil, fmt.Errorf("toml line %d: duplicate key %q", no, name)
+			}
+			table = make(map[string]any)
+			root[name] = append(list, table)
+			continue
+		}
+
+		key, val, ok := strings.Cut(text, "=")
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("toml line %d: expected \"key = value\"", no)
+		}
+		if k, err := strconv.Unquote(key); err == nil {
+			key = k
+		}
+		if _, dup := table[key]; dup {
+			return nil, fmt.Errorf("toml line %d: duplicate key %q", no, key)
+		}
+		v, err := tomlValue(val)
+		if err != nil {
+			return nil, fmt.Errorf("toml line %d: %w", no, err)
+		}
+		table[key] = v
+	}
+	return root, scanner.Err()
+}
+
+func tomlValue(val string) (any, error) {
+	switch {
+	case strings.HasPrefix(val, `"`):
+		return strconv.Unquote(val)
+	case strings.HasPrefix(val, "'"):
+		if len(val) < 2 || !strings.HasSuffix(val, "'") {
+			return nil, fmt.Errorf("unterminated string %s", val)
+		}
+		return val[1 : len(val)-1], nil
+	case val == "true":
+		return true, nil
+	case val == "false":
+		return false, nil
+	}
+	n, err := strconv.ParseInt(strings.ReplaceAll(val, "_", ""), 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported value %s", val)
+	}
+	return n, nil
+}
+
+// stripComment 去掉引号外以#开头的注释，YAML要求#在行首或前面是空白
+func stripComment(line string, toml bool) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (toml || i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,222 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]any
+		err  string // 错误信息包含的内容，为空时不应出错
+	}{
+		{
+			name: "scalars",
+			data: "---\nlevel: info\nmax_days: 7\npath: ~\n",
+			want: map[string]any{"level": "info", "max_days": "7", "path": nil},
+		},
+		{
+			name: "quoted",
+			data: "path: \"logs/app.log\"\ntags: 'it''s'\nlayout: \"{time}\\t{msg}\"\n",
+			want: map[string]any{"path": "logs/app.log", "tags": "it's", "layout": "{time}\t{msg}"},
+		},
+		{
+			name: "comments",
+			data: "# 日志配置\nlevel: info # 缺省等级\npath: \"logs/a#b.log\" # 引号内的#不是注释\ntags: 'db #1'\nencoding: text#json\n",
+			want: map[string]any{"level": "info", "path": "logs/a#b.log", "tags": "db #1", "encoding": "text#json"},
+		},
+		{
+			name: "outputs",
+			data: "level: info\noutputs:\n  - path: logs/error.log\n    level: error\n\n  - path: console # 终端\n    disable_color: true\nasync: true\n",
+			want: map[string]any{
+				"level": "info",
+				"outputs": []any{
+					map[string]any{"path": "logs/error.log", "level": "error"},
+					map[string]any{"path": "console", "disable_color": "true"},
+				},
+				"async": "true",
+			},
+		},
+		{
+			name: "outputs dash on its own line",
+			data: "outputs:\n-\n  path: logs/a.log\n  level: warn\n",
+			want: map[string]any{
+				"outputs": []any{map[string]any{"path": "logs/a.log", "level": "warn"}},
+			},
+		},
+		{
+			name: "outputs aligned with key",
+			data: "outputs:\n- path: logs/a.log\n  level: warn\n- path: console\nlevel: info\n",
+			want: map[string]any{
+				"outputs": []any{
+					map[string]any{"path": "logs/a.log", "level": "warn"},
+					map[string]any{"path": "console"},
+				},
+				"level": "info",
+			},
+		},
+		{name: "item without list", data: "- path: a.log\n", err: "line 1: list item without a key"},
+		{
+			name: "empty list",
+			data: "outputs:\nlevel: info\n",
+			want: map[string]any{"outputs": nil, "level": "info"},
+		},
+		{name: "tab indentation", data: "outputs:\n\t- path: a.log\n", err: "tabs"},
+		{name: "flow sequence", data: "tags: [db, net]\n", err: "flow style"},
+		{name: "flow mapping", data: "outputs: {path: a.log}\n", err: "flow style"},
+		{name: "item indentation", data: "outputs:\n  - path: a.log\n      level: error\n", err: "line 3: unexpected indentation"},
+		{name: "indented scalar", data: "level: info\n  path: a.log\n", err: "line 2: unexpected indentation"},
+		{name: "missing colon", data: "level info\n", err: "expected \"key: value\""},
+		{name: "nested object", data: "outputs:\n  - path:\n", err: "expected \"key: value\""},
+		{name: "duplicate key", data: "level: info\nlevel: warn\n", err: "line 2: duplicate key \"level\""},
+		{name: "duplicate item key", data: "outputs:\n  - path: a.log\n    path: b.log\n", err: "duplicate key \"path\""},
+		{name: "unterminated string", data: "path: 'logs/a.log\n", err: "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseYAML([]byte(tt.data))
+			checkParsed(t, got, err, tt.want, tt.err)
+		})
+	}
+}
+
+func TestParseTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want map[string]any
+		err  string
+	}{
+		{
+			name: "values",
+			data: "level = \"info\"\nmax_days = 7\nmax_size = 1_000\ncompress = 'gzip'\nasync = true\ndisable_color = false\n",
+			want: map[string]any{
+				"level": "info", "max_days": int64(7), "max_size": int64(1000),
+				"compress": "gzip", "async": true, "disable_color": false,
+			},
+		},
+		{
+			name: "comments",
+			data: "# 日志配置\nlevel = \"info\" # 缺省等级\npath = \"logs/a#b.log\"\ntags = 'db #1' # 引号内的#不是注释\nlayout = \"\\\"#\\\" {msg}\"\n",
+			want: map[string]any{"level": "info", "path": "logs/a#b.log", "tags": "db #1", "layout": "\"#\" {msg}"},
+		},
+		{
+			name: "quoted key",
+			data: "\"level\" = \"warn\"\n",
+			want: map[string]any{"level": "warn"},
+		},
+		{
+			name: "outputs",
+			data: "level = \"info\"\n\n[[outputs]]\npath = \"logs/error.log\"\nlevel = \"error\"\n\n[[ outputs ]]\npath = \"console\"\ndisable_color = true\n",
+			want: map[string]any{
+				"level": "info",
+				"outputs": []any{
+					map[string]any{"path": "logs/error.log", "level": "error"},
+					map[string]any{"path": "console", "disable_color": true},
+				},
+			},
+		},
+		{name: "table", data: "[log]\nlevel = \"info\"\n", err: "only arrays of tables"},
+		{name: "missing equals", data: "level \"info\"\n", err: "expected \"key = value\""},
+		{name: "array value", data: "tags = [\"db\", \"net\"]\n", err: "unsupported value"},
+		{name: "bare string", data: "level = info\n", err: "unsupported value"},
+		{name: "duplicate key", data: "level = \"info\"\nlevel = \"warn\"\n", err: "line 2: duplicate key \"level\""},
+		{name: "duplicate item key", data: "[[outputs]]\npath = \"a.log\"\npath = \"b.log\"\n", err: "line 3: duplicate key \"path\""},
+		{name: "key then table array", data: "outputs = 1\n[[outputs]]\n", err: "line 2: duplicate key \"outputs\""},
+		{name: "unterminated string", data: "path = 'logs/a.log\n", err: "unterminated string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseTOML([]byte(tt.data))
+			checkParsed(t, got, err, tt.want, tt.err)
+		})
+	}
+}
+
+func checkParsed(t *testing.T, got map[string]any, err error, want map[string]any, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("got error %v, want error containing %q", err, wantErr)
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %#v, want %#v", got, want)
+	}
+}
+
+// 三种格式的相同配置解析出相同的Option，时长和数字由字符串转换
+func TestParseOptionFormats(t *testing.T) {
+	want := Option{
+		LogLevel:       "info",
+		LogPath:        "logs/app.log",
+		RotateMode:     "interval",
+		RotateInterval: 15 * time.Minute,
+		MaxBackups:     3,
+		Async:          true,
+		Outputs: []OutputOption{
+			{LogPath: "logs/error.log", LogLevel: "error"},
+			{LogPath: "console", DisableLogColor: true},
+		},
+	}
+
+	configs := map[string]string{
+		configFormatJSON: `{
+			"level": "info", "path": "logs/app.log", "rotate_mode": "interval",
+			"rotate_interval": "15m", "max_backups": 3, "async": true,
+			"outputs": [
+				{"path": "logs/error.log", "level": "error"},
+				{"path": "console", "disable_color": true}
+			]
+		}`,
+		configFormatYAML: `
+level: info
+path: logs/app.log
+rotate_mode: interval
+rotate_interval: 15m
+max_backups: 3
+async: true
+outputs:
+  - path: logs/error.log
+    level: error
+  - path: console
+    disable_color: true
+`,
+		configFormatTOML: `
+level = "info"
+path = "logs/app.log"
+rotate_mode = "interval"
+rotate_interval = "15m"
+max_backups = 3
+async = true
+
+[[outputs]]
+path = "logs/error.log"
+level = "error"
+
+[[outputs]]
+path = "console"
+disable_color = true
+`,
+	}
+
+	for format, data := range configs {
+		opt, err := ParseOption([]byte(data), format)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if !reflect.DeepEqual(opt, want) {
+			t.Fatalf("%s: got %+v, want %+v", format, opt, want)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"context"
+	"sync"
+)
+
+// ContextExtractor 从context中提取需要附加到日志的字段
+type ContextExtractor func(ctx context.Context) []Field
+
+type contextKey int
+
+const (
+	traceIDKey contextKey = iota
+	spanIDKey
+	requestIDKey
+	userIDKey
+)
+
+var (
+	extractorsMu sync.RWMutex
+	extractors   = []ContextExtractor{defaultContextExtractor}
+)
+
+// RegisterContextExtractor 注册context字段提取方法，带context的日志都会附加提取到的字段。
+// 缺省已注册提取 ContextWithTraceID 等方法写入的 trace_id、span_id、request_id、user_id
+func RegisterContextExtractor(fn ContextExtractor) {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	extractors = append(extractors, fn)
+}
+
+// ContextWithTraceID 返回带有trace ID的context
+func ContextWithTraceID(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceIDKey, traceID)
+}
+
+// ContextWithSpanID 返回带有span ID的context
+func ContextWithSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, spanIDKey, spanID)
+}
+
+// ContextWithRequestID 返回带有请求ID的context
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// ContextWithUserID 返回带有用户ID的context
+func ContextWithUserID(ctx context.Context, userID any) context.Context {
+	return context.WithValue(ctx, userIDKey, userID)
+}
+
+func defaultContextExtractor(ctx context.Context) []Field {
+	var fields []Field
+	if v, ok := ctx.Value(traceIDKey).(string); ok {
+		fields = append(fields, Field{Key: "trace_id", Value: v})
+	}
+	if v, ok := ctx.Value(spanIDKey).(string); ok {
+		fields = append(fields, Field{Key: "span_id", Value: v})
+	}
+	if v, ok := ctx.Value(requestIDKey).(string); ok {
+		fields = append(fields, Field{Key: "request_id", Value: v})
+	}
+	if v := ctx.Value(userIDKey); v != nil {
+		fields = append(fields, Field{Key: "user_id", Value: v})
+	}
+	return fields
+}
+
+// contextFields 使用所有已注册的提取方法提取context中的字段
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	extractorsMu.RLock()
+	defer extractorsMu.RUnlock()
+	var fields []Field
+	for _, fn := range extractors {
+		fields = append(fields, fn(ctx)...)
+	}
+	return fields
+}
+
+// WithContext 派生一个附带context字段的子日志对象
+func (l *Logger) WithContext(ctx context.Context) *Logger {
+	clone := l.clone()
+	clone.fields = appendFields(l.fields, contextFields(ctx))
+	return clone
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, format string, v ...any) {
+	l.logCtx(ctx, LevelError, 0, format, v...)
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, format string, v ...any) {
+	l.logCtx(ctx, LevelWarn, 0, format, v...)
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, format string, v ...any) {
+	l.logCtx(ctx, LevelInfo, 0, format, v...)
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, format string, v ...any) {
+	l.logCtx(ctx, LevelDebug, 0, format, v...)
+}
+
+func (l *Logger) TraceCtx(ctx context.Context, format string, v ...any) {
+	l.logCtx(ctx, LevelTrace, 0, format, v...)
+}
+
+func (l *Logger) LogCtx(ctx context.Context, level Level, offset int, msg string, args ...any) {
+	l.logCtx(ctx, level, offset, msg, args...)
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultDedupTimeout 重复日志缺省的最长缓存时间
+var defaultDedupTimeout = 30 * time.Second
+
+// dedup 合并连续重复的日志，重复日志被缓存计数，在出现不同日志或超时后
+// 输出一条 "last message repeated N times"
+type dedup struct {
+	timeout time.Duration
+	write   func(e *Entry)
+
+	mu      sync.Mutex
+	last    *Entry
+	lastKey string
+	count   int
+	timer   *time.Timer
+	gen     uint64 // 用于忽略已过期的定时器回调
+}
+
+func newDedup(timeout time.Duration, write func(e *Entry)) *dedup {
+	if timeout <= 0 {
+		timeout = defaultDedupTimeout
+	}
+	return &dedup{timeout: timeout, write: write}
+}
+
+// dedupKey 等级、tag、内容和字段都相同的日志视为重复日志
+func dedupKey(e *Entry) string {
+	return strconv.Itoa(int(e.Level)) + "\x00" + e.Tag + "\x00" + e.Message + "\x00" + formatFields(e.Fields)
+}
+
+// handle 写入日志，与上一条日志重复时只计数。
+// 日志在锁外写入，同步钩子中再输出日志时不会死锁
+func (d *dedup) handle(e *Entry) {
+	key := dedupKey(e)
+
+	d.mu.Lock()
+	if d.last != nil && key == d.lastKey {
+		d.count++
+		if d.count == 1 {
+			gen := d.gen
+			d.timer = time.AfterFunc(d.timeout, func() { d.expire(gen) })
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	repeated := d.takeRepeatedLocked()
+	d.last = e
+	d.lastKey = key
+	d.mu.Unlock()
+
+	if repeated != nil {
+		d.write(repeated)
+	}
+	d.write(e)
+}
+
+func (d *dedup) expire(gen uint64) {
+	var repeated *Entry
+	d.mu.Lock()
+	if gen == d.gen {
+		repeated = d.takeRepeatedLocked()
+	}
+	d.mu.Unlock()
+
+	if repeated != nil {
+		d.write(repeated)
+	}
+}
+
+// takeRepeatedLocked 返回缓存的重复日志计数并清零，没有重复日志时返回nil
+func (d *dedup) takeRepeatedLocked() *Entry {
+	d.gen++
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	if d.count == 0 {
+		return nil
+	}
+
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   d.last.Level,
+		Tag:     d.last.Tag,
+		Caller:  d.last.Caller,
+		Message: fmt.Sprintf("last message repeated %d times", d.count),
+	}
+	d.count = 0
+	return e
+}
+
+// flush 输出缓存的重复日志计数，关闭日志前调用
+func (d *dedup) flush() {
+	d.mu.Lock()
+	repeated := d.takeRepeatedLocked()
+	d.mu.Unlock()
+
+	if repeated != nil {
+		d.write(repeated)
+	}
+}
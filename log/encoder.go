@@ -0,0 +1,325 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encoder 将日志记录编码为输出内容，colorful为true时按等级为终端输出着色
+type encoder interface {
+	Encode(e *Entry, colorful bool) []byte
+}
+
+const (
+	encodingText = "text"
+	encodingJSON = "json"
+)
+
+// defaultLayout 缺省的文本日志格式
+const defaultLayout = "{time} {level} {caller} {tag} {msg}"
+
+// defaultTimeFormat 缺省的文本日志时间格式
+const defaultTimeFormat = "2006-01-02 15:04:05.000"
+
+// 时间格式别名，unix、unixms 输出为时间戳
+const (
+	timeFormatRFC3339   = "rfc3339"
+	timeFormatRFC3339Ms = "rfc3339ms"
+	timeFormatUnix      = "unix"
+	timeFormatUnixMs    = "unixms"
+)
+
+// 等级名称格式
+const (
+	levelFormatShort = "short" // [I]
+	levelFormatFull  = "full"  // [INFO]
+)
+
+// encoderConfig 编码配置，字段含义同Option中的同名字段
+type encoderConfig struct {
+	Encoding    string
+	Layout      string
+	TimeFormat  string
+	TimeZone    string
+	LevelFormat string
+}
+
+func newEncoder(cfg encoderConfig) (encoder, error) {
+	tf, err := newTimeFormatter(cfg.TimeFormat, cfg.TimeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(cfg.Encoding) {
+	case "", encodingText:
+		enc := &textEncoder{time: tf, layout: parseLayout(cfg.Layout)}
+		for _, p := range enc.layout {
+			if p.name == "fields" {
+				enc.hasFields = true
+			}
+		}
+		switch strings.ToLower(cfg.LevelFormat) {
+		case "", levelFormatShort:
+		case levelFormatFull:
+			enc.fullLevel = true
+		default:
+			return nil, fmt.Errorf("unrecognized level format: %q", cfg.LevelFormat)
+		}
+		return enc, nil
+	case encodingJSON:
+		if cfg.TimeFormat == "" {
+			tf.layout = "2006-01-02T15:04:05.000Z07:00"
+		}
+		return &jsonEncoder{time: tf}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized encoding: %q", cfg.Encoding)
+	}
+}
+
+// timeFormatter 按配置的格式和时区格式化日志时间
+type timeFormatter struct {
+	layout string
+	unit   time.Duration // 输出为时间戳时的单位，为0时使用layout格式化
+	loc    *time.Location
+}
+
+func newTimeFormatter(format, zone string) (timeFormatter, error) {
+	tf := timeFormatter{layout: format}
+	switch strings.ToLower(format) {
+	case "":
+		tf.layout = defaultTimeFormat
+	case timeFormatRFC3339:
+		tf.layout = time.RFC3339
+	case timeFormatRFC3339Ms:
+		tf.layout = "2006-01-02T15:04:05.000Z07:00"
+	case timeFormatUnix:
+		tf.unit = time.Second
+	case timeFormatUnixMs:
+		tf.unit = time.Millisecond
+	}
+
+	switch strings.ToLower(zone) {
+	case "", "local":
+	case "utc":
+		tf.loc = time.UTC
+	default:
+		loc, err := time.LoadLocation(zone)
+		if err != nil {
+			return tf, fmt.Errorf("unrecognized time zone: %q", zone)
+		}
+		tf.loc = loc
+	}
+	return tf, nil
+}
+
+// format 返回格式化的时间，以时间戳输出时isNumber为true
+func (tf timeFormatter) format(t time.Time) (s string, isNumber bool) {
+	switch tf.unit {
+	case time.Second:
+		return strconv.FormatInt(t.Unix(), 10), true
+	case time.Millisecond:
+		return strconv.FormatInt(t.UnixMilli(), 10), true
+	}
+	if tf.loc != nil {
+		t = t.In(tf.loc)
+	}
+	return t.Format(tf.layout), false
+}
+
+// layoutPart 文本格式模板的组成部分，name为占位符名称，为空时是普通文本
+type layoutPart struct {
+	name string
+	text string
+}
+
+// parseLayout 解析文本格式模板，支持 {time} {level} {caller} {tag} {msg} {fields}，
+// 模板中没有{fields}时字段跟在{msg}之后
+func parseLayout(layout string) []layoutPart {
+	if layout == "" {
+		layout = defaultLayout
+	}
+
+	var parts []layoutPart
+	for len(layout) > 0 {
+		start := strings.IndexByte(layout, '{')
+		end := strings.IndexByte(layout[max(start, 0):], '}') + max(start, 0)
+		if start < 0 || end < start {
+			parts = append(parts, layoutPart{text: layout})
+			break
+		}
+
+		name := layout[start+1 : end]
+		switch name {
+		case "time", "level", "caller", "tag", "msg", "fields":
+			if start > 0 {
+				parts = append(parts, layoutPart{text: layout[:start]})
+			}
+			parts = append(parts, layoutPart{name: name})
+		default:
+			parts = append(parts, layoutPart{text: layout[:end+1]})
+		}
+		layout = layout[end+1:]
+	}
+	return parts
+}
+
+// textEncoder 文本格式，缺省为：2006-01-02 15:04:05.000 [I] [file:line] [Tag:tag] msg key=value
+type textEncoder struct {
+	time      timeFormatter
+	layout    []layoutPart
+	hasFields bool // 模板中包含{fields}
+	fullLevel bool
+}
+
+func (enc *textEncoder) Encode(e *Entry, colorful bool) []byte {
+	var buf bytes.Buffer
+	skipSpace := false
+	for _, p := range enc.layout {
+		if p.name == "" {
+			text := p.text
+			if skipSpace {
+				// 前一个占位符为空时，去掉其后的分隔空格
+				text = strings.TrimLeft(text, " ")
+			}
+			buf.WriteString(text)
+			skipSpace = false
+			continue
+		}
+
+		value := enc.value(p.name, e, colorful)
+		buf.WriteString(value)
+		skipSpace = value == ""
+	}
+	if skipSpace {
+		buf.Truncate(len(bytes.TrimRight(buf.Bytes(), " ")))
+	}
+	for _, frame := range e.Stack {
+		// 调用栈每层一行，缩进输出
+		buf.WriteString("\n\t")
+		buf.WriteString(frame)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func (enc *textEncoder) value(name string, e *Entry, colorful bool) string {
+	switch name {
+	case "time":
+		s, _ := enc.time.format(e.Time)
+		return s
+	case "level":
+		level := strings.TrimSpace(e.Level.LogPrefix())
+		if enc.fullLevel {
+			level = "[" + strings.ToUpper(e.Level.String()) + "]"
+		}
+		if colorful {
+			level = colorBrushByLevel(e.Level)(level)
+		}
+		return level
+	case "caller":
+		if e.Caller == "" {
+			return ""
+		}
+		return "[" + e.Caller + "]"
+	case "tag":
+		if e.Tag == "" {
+			return ""
+		}
+		return "[Tag:" + e.Tag + "]"
+	case "msg":
+		if enc.hasFields {
+			return e.Message
+		}
+		return e.Message + formatFields(e.Fields)
+	case "fields":
+		return strings.TrimPrefix(formatFields(e.Fields), " ")
+	default:
+		return ""
+	}
+}
+
+// jsonEncoder 每条日志输出为一行JSON，字段平铺在顶层
+type jsonEncoder struct {
+	time timeFormatter
+}
+
+func (enc *jsonEncoder) Encode(e *Entry, _ bool) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	if t, isNumber := enc.time.format(e.Time); isNumber {
+		buf.WriteString(`"time":` + t)
+	} else {
+		appendJSONPair(&buf, "time", t)
+	}
+	buf.WriteByte(',')
+	appendJSONPair(&buf, "level", e.Level.String())
+	if e.Caller != "" {
+		buf.WriteByte(',')
+		appendJSONPair(&buf, "caller", e.Caller)
+	}
+	if e.Tag != "" {
+		buf.WriteByte(',')
+		appendJSONPair(&buf, "tag", e.Tag)
+	}
+	buf.WriteByte(',')
+	appendJSONPair(&buf, "msg", e.Message)
+	for _, f := range e.Fields {
+		buf.WriteByte(',')
+		appendJSONPair(&buf, jsonFieldKey(f.Key), f.Value)
+	}
+	if len(e.Stack) > 0 {
+		buf.WriteByte(',')
+		appendJSONPair(&buf, "stack", e.Stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// jsonFieldKey 字段名与日志的固定字段重名时加上 fields. 前缀，如 msg 输出为 fields.msg，
+// 避免同一个对象中出现重复的key
+func jsonFieldKey(key string) string {
+	switch key {
+	case "time", "level", "caller", "tag", "msg", "stack":
+		return "fields." + key
+	default:
+		return key
+	}
+}
+
+func appendJSONPair(buf *bytes.Buffer, key string, value any) {
+	k, _ := marshalJSON(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(jsonValue(value))
+}
+
+func jsonValue(value any) []byte {
+	switch value.(type) {
+	case error:
+		value = stringify(value)
+	case json.Marshaler:
+	case fmt.Stringer:
+		value = stringify(value)
+	}
+
+	b, err := marshalJSON(value)
+	if err != nil {
+		b, _ = marshalJSON(fmt.Sprint(value))
+	}
+	return b
+}
+
+// marshalJSON 与json.Marshal相同，但不转义HTML字符
+func marshalJSON(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte{'\n'}), nil
+}
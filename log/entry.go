@@ -0,0 +1,14 @@
+package log
+
+import "time"
+
+// Entry 一条日志记录
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Tag     string
+	Caller  string // 调用位置，缺省格式为 file:line，见Option.CallerMode
+	Message string
+	Fields  []Field
+	Stack   []string // 调用栈，每层格式为 "function file:line"，仅在等级达到Option.StackLevel时记录
+}
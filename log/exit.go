@@ -0,0 +1,51 @@
+package log
+
+import (
+	"os"
+	"sync"
+)
+
+// exitFunc Fatal日志退出程序的方法
+var exitFunc = os.Exit
+
+var (
+	exitHooksMu sync.Mutex
+	exitHooks   []func()
+)
+
+// RegisterExitHook 注册退出钩子，Fatal日志在关闭输出、退出程序前按注册顺序执行，
+// 钩子中仍可以输出日志，钩子panic不会影响后续钩子执行和程序退出
+func RegisterExitHook(fn func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, fn)
+}
+
+func runExitHooks() {
+	exitHooksMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitHooksMu.Unlock()
+
+	for _, fn := range hooks {
+		func() {
+			defer func() {
+				_ = recover()
+			}()
+			fn()
+		}()
+	}
+}
+
+// terminate Panic和Fatal等级日志输出后的处理。
+// panic可能被recover，所以Panic只将日志写入输出而不关闭；Fatal执行退出钩子后关闭输出并退出程序
+func (l *Logger) terminate(level Level, msg string) {
+	switch level {
+	case LevelPanic:
+		_ = l.Sync()
+		panic(msg)
+	case LevelFatal:
+		runExitHooks()
+		l.Close()
+		exitFunc(1)
+	}
+}
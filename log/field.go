@@ -0,0 +1,96 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// badKey 键值对参数中，key不是字符串时使用的key
+const badKey = "!BADKEY"
+
+// Field 结构化日志字段
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Any 构造一个日志字段
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// toFields 将 key, value 交替排列的参数转换为字段列表，参数中也可直接传入Field
+func toFields(keysAndValues []any) []Field {
+	if len(keysAndValues) == 0 {
+		return nil
+	}
+
+	fields := make([]Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); {
+		switch k := keysAndValues[i].(type) {
+		case Field:
+			fields = append(fields, k)
+			i++
+		case string:
+			if i+1 >= len(keysAndValues) {
+				// 最后一个key没有对应的value
+				fields = append(fields, Field{Key: badKey, Value: k})
+				i++
+				continue
+			}
+			fields = append(fields, Field{Key: k, Value: keysAndValues[i+1]})
+			i += 2
+		default:
+			fields = append(fields, Field{Key: badKey, Value: k})
+			i++
+		}
+	}
+	return fields
+}
+
+// appendFields 合并字段，不修改原切片
+func appendFields(base, fields []Field) []Field {
+	if len(fields) == 0 {
+		return base
+	}
+	if len(base) == 0 {
+		return fields
+	}
+	merged := make([]Field, 0, len(base)+len(fields))
+	merged = append(merged, base...)
+	return append(merged, fields...)
+}
+
+// formatFields 以 key=value 的文本格式输出字段
+func formatFields(fields []Field) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, f := range fields {
+		sb.WriteByte(' ')
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		sb.WriteString(formatFieldValue(f.Value))
+	}
+	return sb.String()
+}
+
+func formatFieldValue(v any) string {
+	s := stringify(v)
+	if s == "" || strings.ContainsAny(s, " =\"\t\r\n") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// stringify 返回字段值的字符串形式，error和Stringer使用Error、String方法的结果。
+// 通过fmt.Sprint调用这些方法，值为nil指针等导致方法panic时输出 <nil> 或错误提示，不会中断写日志
+func stringify(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
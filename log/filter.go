@@ -0,0 +1,56 @@
+package log
+
+// levelFilter 等级与tag过滤配置，运行时整体原子替换
+type levelFilter struct {
+	level    Level
+	minLevel Level  // 全局等级与tag等级中的最低等级，用于快速过滤
+	tags     tags   // Tag过滤及各Tag的最低等级
+	tagsText string // tag原始配置
+}
+
+func newLevelFilter(level Level, ts tags, tagsText string) *levelFilter {
+	f := &levelFilter{
+		level:    level,
+		minLevel: level,
+		tags:     ts,
+		tagsText: tagsText,
+	}
+	if tagLevel := ts.minLevel(); tagLevel != 0 && tagLevel < f.minLevel {
+		f.minLevel = tagLevel
+	}
+	return f
+}
+
+// Level 返回当前的全局日志等级
+func (l *Logger) Level() Level {
+	return l.filter.Load().level
+}
+
+// SetLevel 修改全局日志等级，可在运行时并发调用，不会重新打开输出
+func (l *Logger) SetLevel(level Level) {
+	for {
+		old := l.filter.Load()
+		if l.filter.CompareAndSwap(old, newLevelFilter(level, old.tags, old.tagsText)) {
+			return
+		}
+	}
+}
+
+// Tags 返回当前的tag配置
+func (l *Logger) Tags() string {
+	return l.filter.Load().tagsText
+}
+
+// SetTags 修改tag配置，格式同 Option.Tags，可在运行时并发调用。配置有误时不做修改并返回错误
+func (l *Logger) SetTags(text string) error {
+	ts, err := parseTags(text)
+	if err != nil {
+		return err
+	}
+	for {
+		old := l.filter.Load()
+		if l.filter.CompareAndSwap(old, newLevelFilter(old.level, ts, text)) {
+			return nil
+		}
+	}
+}
@@ -1,34 +1,203 @@
 package log
 
-var logger = New(Option{
-	DisableLogColor: false,
-	CallerSkip:      1,
-})
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// logger 全局日志对象，调用位置多跳过全局函数这一层
+var logger atomic.Pointer[Logger]
+
+func init() {
+	logger.Store(New(Option{
+		DisableLogColor: false,
+		CallerSkip:      1,
+	}))
+}
+
+// Default 返回全局日志对象，可由调用方直接使用
+func Default() *Logger {
+	l := logger.Load().clone()
+	if l.callerSkip > 0 {
+		l.callerSkip--
+	}
+	return l
+}
+
+// SetDefault 替换全局日志对象，之后全局函数写入l。原来的日志对象不会被关闭，
+// 可以先通过Default取得，用于之后替换回来
+func SetDefault(l *Logger) {
+	g := l.clone()
+	g.callerSkip++
+	logger.Store(g)
+}
 
 func Init(opt Option) {
-	logger.WithOptions(opt)
+	logger.Load().WithOptions(opt)
+}
+
+// Flush 将全局日志对象异步队列中的日志写入输出
+func Flush() error {
+	return logger.Load().Flush()
+}
+
+// Sync 写入全局日志对象缓存的日志，并将文件输出同步到磁盘
+func Sync() error {
+	return logger.Load().Sync()
+}
+
+// Close 关闭全局日志对象的输出
+func Close() {
+	logger.Load().Close()
+}
+
+func Fatal(format string, v ...any) {
+	logger.Load().Fatal(format, v...)
+}
+
+func Panic(format string, v ...any) {
+	logger.Load().Panic(format, v...)
 }
 
 func Error(format string, v ...any) {
-	logger.Error(format, v...)
+	logger.Load().Error(format, v...)
 }
 
 func Warn(format string, v ...any) {
-	logger.Warn(format, v...)
+	logger.Load().Warn(format, v...)
 }
 
 func Info(format string, v ...any) {
-	logger.Info(format, v...)
+	logger.Load().Info(format, v...)
 }
 
 func Debug(format string, v ...any) {
-	logger.Debug(format, v...)
+	logger.Load().Debug(format, v...)
 }
 
 func Trace(format string, v ...any) {
-	logger.Trace(format, v...)
+	logger.Load().Trace(format, v...)
 }
 
 func Log(level Level, offset int, msg string, args ...any) {
-	logger.Log(level, offset, msg, args...)
+	logger.Load().Log(level, offset, msg, args...)
+}
+
+// With 基于全局日志对象派生附带字段的子日志对象
+func With(keysAndValues ...any) *Logger {
+	l := logger.Load().With(keysAndValues...)
+	// 子日志对象由调用方直接使用，不再经过全局函数这一层调用
+	if l.callerSkip > 0 {
+		l.callerSkip--
+	}
+	return l
+}
+
+func Fatalw(msg string, keysAndValues ...any) {
+	logger.Load().Fatalw(msg, keysAndValues...)
+}
+
+func Panicw(msg string, keysAndValues ...any) {
+	logger.Load().Panicw(msg, keysAndValues...)
+}
+
+func Errorw(msg string, keysAndValues ...any) {
+	logger.Load().Errorw(msg, keysAndValues...)
+}
+
+func Warnw(msg string, keysAndValues ...any) {
+	logger.Load().Warnw(msg, keysAndValues...)
+}
+
+func Infow(msg string, keysAndValues ...any) {
+	logger.Load().Infow(msg, keysAndValues...)
+}
+
+func Debugw(msg string, keysAndValues ...any) {
+	logger.Load().Debugw(msg, keysAndValues...)
+}
+
+func Tracew(msg string, keysAndValues ...any) {
+	logger.Load().Tracew(msg, keysAndValues...)
+}
+
+func Logw(level Level, offset int, msg string, keysAndValues ...any) {
+	logger.Load().Logw(level, offset, msg, keysAndValues...)
+}
+
+// Slog 返回一个输出到全局日志对象的 *slog.Logger
+func Slog() *slog.Logger {
+	return logger.Load().Slog()
+}
+
+// SetLevel 修改全局日志对象的日志等级
+func SetLevel(level Level) {
+	logger.Load().SetLevel(level)
+}
+
+// SetTags 修改全局日志对象的tag配置
+func SetTags(text string) error {
+	return logger.Load().SetTags(text)
+}
+
+// LevelHandler 返回查看和修改全局日志对象等级、tag的HTTP接口
+func LevelHandler() http.Handler {
+	return logger.Load().LevelHandler()
+}
+
+// WithContext 基于全局日志对象派生附带context字段的子日志对象
+func WithContext(ctx context.Context) *Logger {
+	l := logger.Load().WithContext(ctx)
+	// 子日志对象由调用方直接使用，不再经过全局函数这一层调用
+	if l.callerSkip > 0 {
+		l.callerSkip--
+	}
+	return l
+}
+
+func ErrorCtx(ctx context.Context, format string, v ...any) {
+	logger.Load().ErrorCtx(ctx, format, v...)
+}
+
+func WarnCtx(ctx context.Context, format string, v ...any) {
+	logger.Load().WarnCtx(ctx, format, v...)
+}
+
+func InfoCtx(ctx context.Context, format string, v ...any) {
+	logger.Load().InfoCtx(ctx, format, v...)
+}
+
+func DebugCtx(ctx context.Context, format string, v ...any) {
+	logger.Load().DebugCtx(ctx, format, v...)
+}
+
+func TraceCtx(ctx context.Context, format string, v ...any) {
+	logger.Load().TraceCtx(ctx, format, v...)
+}
+
+func LogCtx(ctx context.Context, level Level, offset int, msg string, args ...any) {
+	logger.Load().LogCtx(ctx, level, offset, msg, args...)
+}
+
+// AddHook 为全局日志对象添加同步钩子
+func AddHook(hook Hook) {
+	logger.Load().AddHook(hook)
+}
+
+// AddAsyncHook 为全局日志对象添加异步钩子
+func AddAsyncHook(hook Hook) error {
+	return logger.Load().AddAsyncHook(hook)
+}
+
+// Reopen 重新打开全局日志对象的所有文件输出
+func Reopen() error {
+	return logger.Load().Reopen()
+}
+
+// WatchConfig 加载配置文件到全局日志对象，并在文件修改后重新加载
+func WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	return logger.Load().WatchConfig(path, interval)
 }
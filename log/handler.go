@@ -0,0 +1,103 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// levelState 日志等级管理接口的请求与响应内容
+type levelState struct {
+	Level *string `json:"level,omitempty"`
+	Tags  *string `json:"tags,omitempty"`
+}
+
+// levelHandler 运行时查看和修改日志等级、tag的HTTP接口
+type levelHandler struct {
+	l *Logger
+}
+
+// LevelHandler 返回查看和修改日志等级、tag的HTTP接口，可挂载到管理端口上。
+//
+//	GET 返回当前配置，如 {"level":"info","tags":"db:debug"}
+//	PUT 修改配置，支持JSON请求体 {"level":"debug","tags":"db:debug"}，
+//	    或表单、URL参数 level=debug&tags=db:debug，未提供的项保持不变
+func (l *Logger) LevelHandler() http.Handler {
+	return &levelHandler{l: l}
+}
+
+func (h *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		req, err := decodeLevelState(r)
+		if err == nil {
+			err = h.apply(req)
+		}
+		if err != nil {
+			writeLevelJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "only GET and PUT are supported"})
+		return
+	}
+
+	level, tagsText := h.l.Level().String(), h.l.Tags()
+	writeLevelJSON(w, http.StatusOK, levelState{Level: &level, Tags: &tagsText})
+}
+
+// apply 先校验所有配置，全部有效后再修改
+func (h *levelHandler) apply(req levelState) error {
+	var level Level
+	if req.Level != nil {
+		if err := level.UnmarshalText([]byte(*req.Level)); err != nil {
+			return err
+		}
+	}
+	if req.Tags != nil {
+		if _, err := parseTags(*req.Tags); err != nil {
+			return err
+		}
+		_ = h.l.SetTags(*req.Tags)
+	}
+	if req.Level != nil {
+		h.l.SetLevel(level)
+	}
+	return nil
+}
+
+func decodeLevelState(r *http.Request) (req levelState, err error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err = json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return req, fmt.Errorf("invalid request body: %s", err)
+		}
+		return req, nil
+	}
+
+	if err = r.ParseForm(); err != nil {
+		return req, fmt.Errorf("invalid request: %s", err)
+	}
+	if r.Form.Has("level") {
+		level := r.Form.Get("level")
+		req.Level = &level
+	}
+	if r.Form.Has("tags") {
+		tagsText := r.Form.Get("tags")
+		req.Tags = &tagsText
+	}
+	if req.Level == nil && req.Tags == nil {
+		return req, errors.New("level or tags is required")
+	}
+	return req, nil
+}
+
+func writeLevelJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,164 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sync"
+
+	"github.com/zngw/golib/zchan"
+)
+
+// Hook 日志钩子，日志写入输出后对订阅等级的日志调用Fire
+type Hook interface {
+	Levels() []Level     // 订阅的日志等级
+	Fire(e *Entry) error // 处理日志，不能修改e
+}
+
+type funcHook struct {
+	levels []Level
+	fire   func(e *Entry) error
+}
+
+func (h *funcHook) Levels() []Level {
+	return h.levels
+}
+
+func (h *funcHook) Fire(e *Entry) error {
+	return h.fire(e)
+}
+
+// NewHook 使用函数创建钩子，levels为订阅的日志等级
+func NewHook(fire func(e *Entry) error, levels ...Level) Hook {
+	return &funcHook{levels: levels, fire: fire}
+}
+
+// hookRunner 执行钩子，异步钩子通过无限缓存chan交给后台协程执行
+type hookRunner struct {
+	hook   Hook
+	levels map[Level]bool
+
+	mu     sync.RWMutex
+	async  *zchan.ZChan[*Entry]
+	closed bool
+	done   chan struct{}
+}
+
+func newHookRunner(hook Hook, async bool) (*hookRunner, error) {
+	r := &hookRunner{
+		hook:   hook,
+		levels: make(map[Level]bool),
+	}
+	for _, level := range hook.Levels() {
+		r.levels[level] = true
+	}
+
+	if async {
+		ch, err := zchan.New[*Entry](64)
+		if err != nil {
+			return nil, err
+		}
+		r.async = ch
+		r.done = make(chan struct{})
+		go r.run()
+	}
+	return r, nil
+}
+
+func (r *hookRunner) run() {
+	defer close(r.done)
+	for e := range r.async.Out {
+		r.fire(e)
+	}
+}
+
+func (r *hookRunner) handle(e *Entry) {
+	if !r.levels[e.Level] {
+		return
+	}
+	if r.async == nil {
+		r.fire(e)
+		return
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if !r.closed {
+		r.async.In <- e
+	}
+}
+
+// fire 执行钩子，钩子的错误和panic输出到标准错误，不影响日志输出
+func (r *hookRunner) fire(e *Entry) {
+	defer func() {
+		if err := recover(); err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "log: hook panic: %v\n%s", err, debug.Stack())
+		}
+	}()
+
+	if err := r.hook.Fire(e); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "log: failed to fire hook: %v\n", err)
+	}
+}
+
+// close 等待异步钩子处理完已提交的日志
+func (r *hookRunner) close() {
+	if r.async == nil {
+		return
+	}
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return
+	}
+	r.closed = true
+	close(r.async.In)
+	r.mu.Unlock()
+
+	<-r.done
+}
+
+// AddHook 添加同步钩子，钩子在写日志的协程中执行
+func (l *Logger) AddHook(hook Hook) {
+	r, _ := newHookRunner(hook, false)
+	l.addHookRunner(r)
+}
+
+// AddAsyncHook 添加异步钩子，钩子在后台协程中按日志顺序执行，Close时等待已提交的日志处理完
+func (l *Logger) AddAsyncHook(hook Hook) error {
+	r, err := newHookRunner(hook, true)
+	if err != nil {
+		return err
+	}
+	l.addHookRunner(r)
+	return nil
+}
+
+func (l *Logger) addHookRunner(r *hookRunner) {
+	l.hooksMu.Lock()
+	defer l.hooksMu.Unlock()
+	hooks := make([]*hookRunner, 0, len(l.hooks)+1)
+	hooks = append(hooks, l.hooks...)
+	l.hooks = append(hooks, r)
+}
+
+func (l *Logger) fireHooks(e *Entry) {
+	l.hooksMu.RLock()
+	hooks := l.hooks
+	l.hooksMu.RUnlock()
+
+	for _, r := range hooks {
+		r.handle(e)
+	}
+}
+
+func (l *Logger) closeHooks() {
+	l.hooksMu.RLock()
+	hooks := l.hooks
+	l.hooksMu.RUnlock()
+
+	for _, r := range hooks {
+		r.close()
+	}
+}
@@ -17,6 +17,8 @@ const (
 	LevelInfo
 	LevelWarn
 	LevelError
+	LevelPanic // 输出日志后panic
+	LevelFatal // 输出日志后执行退出钩子、关闭输出并退出程序
 )
 
 // ParseLevel parses a level based on the lower-case or all-caps ASCII
@@ -44,6 +46,10 @@ func (l Level) String() string {
 		return "warn"
 	case LevelError:
 		return "error"
+	case LevelPanic:
+		return "panic"
+	case LevelFatal:
+		return "fatal"
 	default:
 		return fmt.Sprintf("Level(%d)", l)
 	}
@@ -61,6 +67,10 @@ func (l Level) LogPrefix() string {
 		return "[W] "
 	case LevelError:
 		return "[E] "
+	case LevelPanic:
+		return "[P] "
+	case LevelFatal:
+		return "[F] "
 	default:
 		return ""
 	}
@@ -99,6 +109,10 @@ func (l *Level) unmarshalText(text []byte) bool {
 		*l = LevelWarn
 	case "error", "ERROR":
 		*l = LevelError
+	case "panic", "PANIC":
+		*l = LevelPanic
+	case "fatal", "FATAL":
+		*l = LevelFatal
 	default:
 		return false
 	}
@@ -1,13 +1,15 @@
 package log
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
-	"path"
-	"runtime"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -16,91 +18,289 @@ type writer interface {
 	CloseLog()
 }
 
+// flusher 带缓存的输出，Flush将缓存的日志写入下层输出
+type flusher interface {
+	Flush() error
+}
+
+// syncer 可以将已写入的日志同步到磁盘的输出
+type syncer interface {
+	Sync() error
+}
+
+// reopener 可以重新打开日志文件的输出
+type reopener interface {
+	Reopen() error
+}
+
 var defaultWriter = os.Stdout
 
 type Logger struct {
-	outMu sync.Mutex
-	out   io.Writer
+	*core // 输出配置，With派生的子日志对象与父对象共享
+
+	fields     []Field // 子日志对象附带的字段
+	callerSkip int
+}
+
+type core struct {
+	mu      sync.RWMutex // 保护outputs、enc及调用位置配置，修改配置时与写日志互斥
+	outputs []*output
 
-	level         Level
-	tags          tags
+	enc           encoder
+	filter        atomic.Pointer[levelFilter]
 	colorful      bool
 	callerEnabled bool
-	callerSkip    int
+	callerMode    callerMode
+	stackLevel    Level // 达到该等级的日志附带调用栈，为0时不记录
+
+	hooksMu sync.RWMutex
+	hooks   []*hookRunner
+
+	sampler  atomic.Pointer[sampler]
+	dedup    atomic.Pointer[dedup]
+	redactor atomic.Pointer[redactor]
+
+	writer io.Writer // Option.Writer，配置文件无法设置，重新加载时保留
+
+	stopMu           sync.Mutex // 保护stopReopenSignal和stopWatchConfig
+	stopReopenSignal func()     // 停止监听重新打开日志文件的信号
+	stopWatchConfig  func()     // 停止监听配置文件
 }
 
 // New 新建日志对象， 使用`opt ...`的目的是为了让New可以缺省参数使用，实际只使用到了opt[0]
 func New(opt ...Option) *Logger {
-	l := &Logger{}
+	l := &Logger{core: &core{}}
+	l.filter.Store(newLevelFilter(LevelTrace, tags{}, ""))
 
 	if len(opt) > 0 {
 		l.WithOptions(opt[0])
 	}
 
-	if l.out == nil {
-		l.out = defaultWriter
+	if l.outputs == nil {
+		l.outputs = []*output{{w: defaultWriter}}
 	}
-	if l.level == 0 {
-		l.level = LevelTrace
+	if l.enc == nil {
+		l.enc, _ = newEncoder(encoderConfig{})
 	}
 	return l
 }
 
 // WithOptions 修改当前的日志配置
 func (l *Logger) WithOptions(opt Option) {
-	if l.out == nil || opt.LogPath != "" {
-		if l.out != nil {
-			if lw, ok := l.out.(writer); ok {
-				lw.CloseLog()
+	l.applyOptions(opt, nil)
+}
+
+// applyOptions 应用日志配置。old不为nil时只更新与old不同的部分，未变化的输出不会重新打开，
+// 用于重新加载配置文件
+func (l *Logger) applyOptions(opt Option, old *Option) {
+	full := old == nil
+	if full {
+		old = &Option{}
+	}
+	changed := func(parts ...any) bool {
+		if full {
+			return true
+		}
+		for i := 0; i < len(parts); i += 2 {
+			if parts[i] != parts[i+1] {
+				return true
 			}
 		}
+		return false
+	}
 
-		if opt.LogPath == "" || opt.LogPath == "console" {
-			l.out = newConsoleWriter(consoleConfig{
-				Colorful: !opt.DisableLogColor,
-			})
-		} else {
-			writer := newRotateFileWriter(rotateFileConfig{
-				FileName: opt.LogPath,
-				Mode:     rotateFileModeDaily,
-				MaxDays:  opt.MaxDays,
-			})
-			writer.Init()
-			l.out = writer
+	cfg := newEncoderConfig(opt)
+
+	// 重新加载时只在输出配置变化后重建输出，写入器配置未变化的输出复用原有写入器。
+	// 新旧配置都没有设置输出时保留原有输出，与首次加载时的WithOptions一致
+	var outputs, unused []*output
+	if full {
+		if l.outputs == nil || opt.LogPath != "" || len(opt.Outputs) > 0 || opt.Writer != nil {
+			outputs, unused = newOutputs(opt, l.outputs)
 		}
+	} else if outputsChanged(opt, *old) &&
+		(opt.LogPath != "" || len(opt.Outputs) > 0 || old.LogPath != "" || len(old.Outputs) > 0) {
+		l.mu.RLock()
+		opt.Writer = l.writer
+		l.mu.RUnlock()
+		outputs, unused = newOutputs(opt, l.outputs)
+	}
+
+	var enc encoder
+	if l.enc == nil || (full && cfg != (encoderConfig{})) || (!full && cfg != newEncoderConfig(*old)) {
+		enc, _ = newEncoder(cfg)
 	}
 
-	if opt.LogLevel != "" {
-		level, err := parseLevel(opt.LogLevel)
-		if err == nil {
-			l.level = level
+	l.mu.Lock()
+	if outputs != nil {
+		l.outputs = outputs
+	}
+	if full && opt.Writer != nil {
+		l.writer = opt.Writer
+	}
+	if enc != nil {
+		l.enc = enc
+	}
+	if changed(opt.StackLevel, old.StackLevel) {
+		l.stackLevel = 0
+		if opt.StackLevel != "" {
+			level, err := parseLevel(opt.StackLevel)
+			if err == nil {
+				l.stackLevel = level
+			}
 		}
 	}
+	if changed(opt.DisableCaller, old.DisableCaller, opt.CallerMode, old.CallerMode) {
+		l.callerEnabled = !opt.DisableCaller
+		l.callerMode, _ = parseCallerMode(opt.CallerMode)
+	}
+	l.mu.Unlock()
 
-	l.tags = parseTags(opt.Tags)
-	l.callerEnabled = !opt.DisableCaller
-	if opt.CallerSkip > 0 {
+	// 替换后关闭不再使用的输出，写入其中缓存的日志
+	for _, o := range unused {
+		o.close()
+	}
+
+	if changed(opt.LogLevel, old.LogLevel, opt.Tags, old.Tags) {
+		level := l.Level()
+		if opt.LogLevel != "" {
+			lvl, err := parseLevel(opt.LogLevel)
+			if err == nil {
+				level = lvl
+			}
+		}
+
+		ts, _ := parseTags(opt.Tags)
+		l.filter.Store(newLevelFilter(level, ts, opt.Tags))
+	}
+
+	if changed(opt.SampleInitial, old.SampleInitial, opt.SampleThereafter, old.SampleThereafter,
+		opt.SampleInterval, old.SampleInterval) {
+		var s *sampler
+		if opt.SampleInitial > 0 {
+			s = newSampler(samplerConfig{
+				Initial:    opt.SampleInitial,
+				Thereafter: opt.SampleThereafter,
+				Interval:   opt.SampleInterval,
+			}, l.reportSampled)
+		}
+		if prev := l.sampler.Swap(s); prev != nil {
+			prev.stop()
+		}
+	}
+
+	if changed(opt.RedactFields, old.RedactFields, opt.RedactRules, old.RedactRules) {
+		r, _ := newRedactor(opt.RedactFields, opt.RedactRules)
+		l.redactor.Store(r)
+	}
+
+	if changed(opt.CollapseRepeated, old.CollapseRepeated, opt.CollapseTimeout, old.CollapseTimeout) {
+		var d *dedup
+		if opt.CollapseRepeated {
+			d = newDedup(opt.CollapseTimeout, l.writeEntry)
+		}
+		if prev := l.dedup.Swap(d); prev != nil {
+			prev.flush()
+		}
+	}
+
+	if changed(opt.ReopenSignal, old.ReopenSignal) {
+		var stop func()
+		if opt.ReopenSignal {
+			stop = l.ReopenOnSignal()
+		}
+		if prev := l.swapStopFunc(&l.stopReopenSignal, stop); prev != nil {
+			prev()
+		}
+	}
+
+	if full && opt.CallerSkip > 0 {
 		l.callerSkip = opt.CallerSkip
 	}
-	return
+}
+
+// outputsChanged 两份配置中创建输出用到的配置是否不同
+func outputsChanged(opt, old Option) bool {
+	if opt.LogPath != old.LogPath || !slices.Equal(opt.Outputs, old.Outputs) ||
+		opt.DisableLogColor != old.DisableLogColor || opt.LevelFiles != old.LevelFiles {
+		return true
+	}
+	// 附加输出的编码格式基于主配置
+	if newEncoderConfig(opt) != newEncoderConfig(old) {
+		return true
+	}
+	return opt.RotateMode != old.RotateMode || opt.MaxDays != old.MaxDays || opt.MaxSize != old.MaxSize ||
+		opt.MaxBackups != old.MaxBackups || opt.MaxTotalSize != old.MaxTotalSize ||
+		opt.RotateInterval != old.RotateInterval || opt.MaxAge != old.MaxAge || opt.Compress != old.Compress ||
+		opt.Async != old.Async || opt.AsyncQueueSize != old.AsyncQueueSize || opt.AsyncOverflow != old.AsyncOverflow
+}
+
+// swapStopFunc 替换停止监听的函数并返回原函数。停止时需要等待监听协程退出，
+// 原函数由调用方在锁外调用，避免与正在重新加载配置的协程死锁
+func (c *core) swapStopFunc(p *func(), stop func()) func() {
+	c.stopMu.Lock()
+	defer c.stopMu.Unlock()
+	prev := *p
+	*p = stop
+	return prev
 }
 
 func (l *Logger) clone() *Logger {
 	clone := &Logger{
-		out:           l.out,
-		level:         l.level,
-		callerEnabled: l.callerEnabled,
-		callerSkip:    l.callerSkip,
+		core:       l.core,
+		fields:     l.fields,
+		callerSkip: l.callerSkip,
 	}
 	return clone
 }
 
+// With 派生一个附带字段的子日志对象，子对象输出的每条日志都会带上这些字段。
+// keysAndValues 为 key, value 交替排列的参数，也可以直接传入Field
+func (l *Logger) With(keysAndValues ...any) *Logger {
+	clone := l.clone()
+	clone.fields = appendFields(l.fields, toFields(keysAndValues))
+	return clone
+}
+
 func (l *Logger) log(level Level, offset int, msg string, args ...any) {
-	show := l.level.Enabled(level)
-	if !show {
+	show := l.filter.Load().minLevel.Enabled(level)
+	if !show && level < LevelPanic {
 		return
 	}
 
+	tag, msg, args := splitTag(msg, args)
+	if !l.check(level, tag, msg) && level < LevelPanic {
+		return
+	}
+
+	message := getMessage(msg, args)
+	l.output(level, offset, tag, message, nil)
+	if level >= LevelPanic {
+		l.terminate(level, message)
+	}
+}
+
+// logCtx 与log相同，附加从ctx中提取的字段。调用层级需与log保持一致
+func (l *Logger) logCtx(ctx context.Context, level Level, offset int, msg string, args ...any) {
+	show := l.filter.Load().minLevel.Enabled(level)
+	if !show && level < LevelPanic {
+		return
+	}
+
+	tag, msg, args := splitTag(msg, args)
+	if !l.check(level, tag, msg) && level < LevelPanic {
+		return
+	}
+
+	message := getMessage(msg, args)
+	l.output(level, offset, tag, message, contextFields(ctx))
+	if level >= LevelPanic {
+		l.terminate(level, message)
+	}
+}
+
+// splitTag format不带'%'占位符且后面存在参数时，format为tag，args[0]为日志内容
+func splitTag(msg string, args []any) (string, string, []any) {
 	tag := ""
 	if len(args) > 0 && strings.Count(msg, "%")-strings.Count(msg, "%%")*2 == 0 {
 		// 大于一个参数，有占位符
@@ -108,36 +308,170 @@ func (l *Logger) log(level Level, offset int, msg string, args ...any) {
 		msg = args[0].(string)
 		args = args[1:]
 	}
+	return tag, msg, args
+}
 
-	tag, show = l.tags.GetTag(tag)
-	if !show {
+func (l *Logger) logw(level Level, offset int, msg string, keysAndValues []any) {
+	show := l.Level().Enabled(level)
+	if !show && level < LevelPanic {
+		return
+	}
+	if !l.check(level, "", msg) && level < LevelPanic {
 		return
-	} else {
-		msg = tag + msg
 	}
 
-	caller := ""
-	if l.callerEnabled {
-		caller = getCallerPrefix(3 + l.callerSkip + offset)
+	l.output(level, offset, "", msg, toFields(keysAndValues))
+	if level >= LevelPanic {
+		l.terminate(level, msg)
+	}
+}
+
+// check 按等级、tag过滤日志，并对重复日志采样。Panic和Fatal日志由调用方保证一定输出
+func (l *Logger) check(level Level, tag, template string) bool {
+	f := l.filter.Load()
+	if !f.tags.Enabled(tag, level, f.level) {
+		return false
+	}
+	if s := l.sampler.Load(); s != nil && level < LevelPanic {
+		return s.allow(sampleKey{level: level, tag: tag, template: template})
+	}
+	return true
+}
+
+func (l *Logger) output(level Level, offset int, tag, msg string, fields []Field) {
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   level,
+		Tag:     tag,
+		Message: msg,
+		Fields:  appendFields(l.fields, fields),
+	}
+	callerEnabled, callerMode, stackLevel := l.callerConfig()
+	if callerEnabled {
+		e.Caller = getCaller(4+l.callerSkip+offset, callerMode)
+	}
+	if stackLevel != 0 && level >= stackLevel {
+		e.Stack = captureStack()
 	}
 
-	outMsg := fmt.Sprintf("%s%s%s%s\n",
-		time.Now().Format("2006-01-02 15:04:05.000 "),
-		level.LogPrefix(),
-		caller,
-		getMessage(msg, args),
-	)
+	l.write(e)
+}
+
+// callerConfig 返回调用位置及调用栈配置
+func (c *core) callerConfig() (enabled bool, mode callerMode, stackLevel Level) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.callerEnabled, c.callerMode, c.stackLevel
+}
 
-	if lw, ok := l.out.(writer); ok {
-		l.outMu.Lock()
-		defer l.outMu.Unlock()
-		_, _ = lw.WriteLog([]byte(outMsg), level)
+// write 写入日志记录，先脱敏，开启合并重复日志时再经过合并处理
+func (l *Logger) write(e *Entry) {
+	if r := l.redactor.Load(); r != nil {
+		r.redact(e)
+	}
+	if d := l.dedup.Load(); d != nil {
+		d.handle(e)
 		return
 	}
+	l.writeEntry(e)
+}
+
+// writeEntry 编码日志记录并写入所有匹配的输出，使用相同编码格式的输出只编码一次
+func (l *Logger) writeEntry(e *Entry) {
+	var plain, colored []byte
+	l.mu.RLock()
+	for _, o := range l.outputs {
+		if !o.enabled(e) {
+			continue
+		}
 
-	l.outMu.Lock()
-	defer l.outMu.Unlock()
-	_, _ = l.out.Write([]byte(outMsg))
+		switch {
+		case o.enc != nil:
+			o.write(o.enc.Encode(e, o.colorful), e.Level)
+		case o.colorful:
+			if colored == nil {
+				colored = l.enc.Encode(e, true)
+			}
+			o.write(colored, e.Level)
+		default:
+			if plain == nil {
+				plain = l.enc.Encode(e, false)
+			}
+			o.write(plain, e.Level)
+		}
+	}
+	l.mu.RUnlock()
+
+	l.fireHooks(e)
+}
+
+// Flush 将异步队列中的日志写入输出，非异步模式下直接返回
+func (l *Logger) Flush() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var errs []error
+	for _, o := range l.outputs {
+		errs = append(errs, o.flush())
+	}
+	return errors.Join(errs...)
+}
+
+// Sync 写入缓存的日志，并将文件输出同步到磁盘
+func (l *Logger) Sync() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var errs []error
+	for _, o := range l.outputs {
+		errs = append(errs, o.sync())
+	}
+	return errors.Join(errs...)
+}
+
+// Reopen 重新打开所有文件输出，配合logrotate的create模式使用
+func (l *Logger) Reopen() error {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var errs []error
+	for _, o := range l.outputs {
+		errs = append(errs, o.reopen())
+	}
+	return errors.Join(errs...)
+}
+
+// Close 写入缓存的日志后关闭输出，程序退出前调用以免丢失异步队列中的日志
+func (l *Logger) Close() {
+	if stop := l.swapStopFunc(&l.stopWatchConfig, nil); stop != nil {
+		stop()
+	}
+	if stop := l.swapStopFunc(&l.stopReopenSignal, nil); stop != nil {
+		stop()
+	}
+	if s := l.sampler.Swap(nil); s != nil {
+		s.stop()
+	}
+	if d := l.dedup.Swap(nil); d != nil {
+		d.flush()
+	}
+	l.closeHooks()
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	for _, o := range l.outputs {
+		o.close()
+	}
+}
+
+// Fatal 输出日志后执行退出钩子，关闭输出并以状态码1退出程序
+func (l *Logger) Fatal(format string, v ...any) {
+	l.log(LevelFatal, 0, format, v...)
+}
+
+// Panic 输出日志并将缓存的日志写入输出后，以日志内容panic
+func (l *Logger) Panic(format string, v ...any) {
+	l.log(LevelPanic, 0, format, v...)
 }
 
 func (l *Logger) Error(format string, v ...any) {
@@ -164,6 +498,41 @@ func (l *Logger) Log(level Level, offset int, msg string, args ...any) {
 	l.log(level, offset, msg, args...)
 }
 
+// Fatalw 输出带字段的Fatal日志，退出处理与Fatal相同
+func (l *Logger) Fatalw(msg string, keysAndValues ...any) {
+	l.logw(LevelFatal, 0, msg, keysAndValues)
+}
+
+// Panicw 输出带字段的Panic日志，panic处理与Panic相同
+func (l *Logger) Panicw(msg string, keysAndValues ...any) {
+	l.logw(LevelPanic, 0, msg, keysAndValues)
+}
+
+// Errorw 输出带字段的日志，keysAndValues 为 key, value 交替排列的参数
+func (l *Logger) Errorw(msg string, keysAndValues ...any) {
+	l.logw(LevelError, 0, msg, keysAndValues)
+}
+
+func (l *Logger) Warnw(msg string, keysAndValues ...any) {
+	l.logw(LevelWarn, 0, msg, keysAndValues)
+}
+
+func (l *Logger) Infow(msg string, keysAndValues ...any) {
+	l.logw(LevelInfo, 0, msg, keysAndValues)
+}
+
+func (l *Logger) Debugw(msg string, keysAndValues ...any) {
+	l.logw(LevelDebug, 0, msg, keysAndValues)
+}
+
+func (l *Logger) Tracew(msg string, keysAndValues ...any) {
+	l.logw(LevelTrace, 0, msg, keysAndValues)
+}
+
+func (l *Logger) Logw(level Level, offset int, msg string, keysAndValues ...any) {
+	l.logw(level, offset, msg, keysAndValues)
+}
+
 func getMessage(template string, fmtArgs []any) string {
 	if len(fmtArgs) == 0 {
 		return template
@@ -180,13 +549,3 @@ func getMessage(template string, fmtArgs []any) string {
 	}
 	return fmt.Sprint(fmtArgs...)
 }
-
-func getCallerPrefix(skip int) string {
-	_, file, line, ok := runtime.Caller(skip)
-	if !ok {
-		file = "???"
-		line = 0
-	}
-	_, file = path.Split(file)
-	return fmt.Sprintf("[%s:%d] ", file, line)
-}
@@ -0,0 +1,250 @@
+// Package logtest 测试中记录日志并断言日志内容，如
+//
+//	func TestQuery(t *testing.T) {
+//		rec := logtest.ReplaceGlobal(t)
+//		Query()
+//		rec.AssertLogged(t, logtest.Match{Level: log.LevelError, Tag: "db"})
+//	}
+package logtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/zngw/golib/log"
+)
+
+var allLevels = []log.Level{
+	log.LevelTrace, log.LevelDebug, log.LevelInfo, log.LevelWarn,
+	log.LevelError, log.LevelPanic, log.LevelFatal,
+}
+
+// Recorder 记录日志的钩子，保存每条日志的等级、tag、内容、字段及调用位置
+type Recorder struct {
+	mu      sync.Mutex
+	entries []log.Entry
+}
+
+// NewRecorder 创建Recorder，通过 Logger.AddHook 添加到日志对象
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Levels 订阅所有等级的日志
+func (r *Recorder) Levels() []log.Level {
+	return allLevels
+}
+
+// Fire 保存日志的副本
+func (r *Recorder) Fire(e *log.Entry) error {
+	entry := *e
+	entry.Fields = append([]log.Field(nil), e.Fields...)
+	entry.Stack = append([]string(nil), e.Stack...)
+
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+	return nil
+}
+
+// Entries 返回已记录的日志
+func (r *Recorder) Entries() []log.Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]log.Entry(nil), r.entries...)
+}
+
+// Reset 清空已记录的日志
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+// Match 日志匹配条件，零值的条件不参与匹配
+type Match struct {
+	Level   log.Level
+	Tag     string
+	Message string         // 日志内容包含该字符串
+	Fields  map[string]any // 包含这些字段，值按 fmt.Sprint 的结果比较
+}
+
+func (m Match) match(e log.Entry) bool {
+	if m.Level != 0 && e.Level != m.Level {
+		return false
+	}
+	if m.Tag != "" && e.Tag != m.Tag {
+		return false
+	}
+	if m.Message != "" && !strings.Contains(e.Message, m.Message) {
+		return false
+	}
+	for k, v := range m.Fields {
+		found := false
+		for _, f := range e.Fields {
+			if f.Key == k && fmt.Sprint(f.Value) == fmt.Sprint(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (m Match) String() string {
+	var parts []string
+	if m.Level != 0 {
+		parts = append(parts, "level="+m.Level.String())
+	}
+	if m.Tag != "" {
+		parts = append(parts, "tag="+m.Tag)
+	}
+	if m.Message != "" {
+		parts = append(parts, fmt.Sprintf("message contains %q", m.Message))
+	}
+	if len(m.Fields) > 0 {
+		parts = append(parts, fmt.Sprintf("fields=%v", m.Fields))
+	}
+	if len(parts) == 0 {
+		return "any entry"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Find 返回匹配的日志
+func (r *Recorder) Find(m Match) []log.Entry {
+	var found []log.Entry
+	for _, e := range r.Entries() {
+		if m.match(e) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// Has 是否有匹配的日志
+func (r *Recorder) Has(m Match) bool {
+	return len(r.Find(m)) > 0
+}
+
+// Contains 是否有指定等级和tag的日志，如 Contains(log.LevelError, "db")
+func (r *Recorder) Contains(level log.Level, tag string) bool {
+	return r.Has(Match{Level: level, Tag: tag})
+}
+
+// AssertLogged 没有匹配的日志时测试失败，并列出已记录的日志
+func (r *Recorder) AssertLogged(t testing.TB, m Match) {
+	t.Helper()
+	if !r.Has(m) {
+		t.Errorf("logtest: no entry matches %s, recorded:\n%s", m, r.dump())
+	}
+}
+
+// AssertNotLogged 存在匹配的日志时测试失败
+func (r *Recorder) AssertNotLogged(t testing.TB, m Match) {
+	t.Helper()
+	if found := r.Find(m); len(found) > 0 {
+		t.Errorf("logtest: %d entries unexpectedly match %s, first: %s", len(found), m, format(found[0]))
+	}
+}
+
+// AssertCount 匹配的日志条数不等于n时测试失败
+func (r *Recorder) AssertCount(t testing.TB, m Match, n int) {
+	t.Helper()
+	if found := r.Find(m); len(found) != n {
+		t.Errorf("logtest: want %d entries matching %s, got %d, recorded:\n%s", n, m, len(found), r.dump())
+	}
+}
+
+func (r *Recorder) dump() string {
+	entries := r.Entries()
+	if len(entries) == 0 {
+		return "\t(none)"
+	}
+	var b strings.Builder
+	for _, e := range entries {
+		b.WriteString("\t")
+		b.WriteString(format(e))
+		b.WriteString("\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func format(e log.Entry) string {
+	s := fmt.Sprintf("[%s]", e.Level)
+	if e.Caller != "" {
+		s += " [" + e.Caller + "]"
+	}
+	if e.Tag != "" {
+		s += " [Tag:" + e.Tag + "]"
+	}
+	s += " " + e.Message
+	for _, f := range e.Fields {
+		s += fmt.Sprintf(" %s=%v", f.Key, f.Value)
+	}
+	return s
+}
+
+// tbWriter 将终端输出转到 t.Log，测试失败或使用 -v 时显示。测试结束后丢弃，避免 t.Log panic
+type tbWriter struct {
+	mu   sync.Mutex
+	t    testing.TB
+	done bool
+}
+
+func (w *tbWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	}
+	return len(p), nil
+}
+
+func (w *tbWriter) stop() {
+	w.mu.Lock()
+	w.done = true
+	w.mu.Unlock()
+}
+
+// New 创建测试用的日志对象，日志记录到返回的Recorder，终端输出转到 t.Log。
+// opt可以修改等级、tag等配置，测试结束时关闭日志对象
+func New(t testing.TB, opt ...log.Option) (*log.Logger, *Recorder) {
+	t.Helper()
+
+	var o log.Option
+	if len(opt) > 0 {
+		o = opt[0]
+	}
+	o.DisableLogColor = true
+	w := &tbWriter{t: t}
+	if o.Writer == nil {
+		o.Writer = w
+	}
+
+	l := log.New(o)
+	r := NewRecorder()
+	l.AddHook(r)
+
+	// Cleanup 后注册的先执行，先关闭日志对象写完缓存的日志，再停止 t.Log 输出
+	t.Cleanup(w.stop)
+	t.Cleanup(l.Close)
+	return l, r
+}
+
+// ReplaceGlobal 创建测试用的日志对象替换全局日志对象，测试结束后恢复。
+// 全局日志对象是共享的，使用该函数的测试不能并行执行
+func ReplaceGlobal(t testing.TB, opt ...log.Option) *Recorder {
+	t.Helper()
+
+	l, r := New(t, opt...)
+	prev := log.Default()
+	log.SetDefault(l)
+	t.Cleanup(func() { log.SetDefault(prev) })
+	return r
+}
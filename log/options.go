@@ -1,12 +1,71 @@
 package log
 
-// Option 日志配置
+import (
+	"io"
+	"time"
+)
+
+// Option 日志配置。标签中的名称是 ParseOption、LoadOptionFromFile、LoadOptionFromEnv 使用的配置项名称，
+// 时长使用 "15m"、"1h30m" 等格式。直接用 encoding/json 等解码时时长字段按纳秒整数读取，
+// 无法识别 "15m"，从配置文件读取时应使用 LoadOptionFromFile 或 ParseOption
 type Option struct {
-	LogPath         string // 日志输入文件， console为终端输出
-	LogLevel        string // 日志等级
-	Tags            string // 日志Tag
-	MaxDays         int    // 日志文件保留日期
-	DisableLogColor bool   // 终端输出是否显示颜色
-	DisableCaller   bool   // 是否打印调用文件
-	CallerSkip      int    // 打印文件级
+	LogPath         string        `json:"path" yaml:"path" toml:"path"`                                     // 日志输入文件， console为终端输出
+	LogLevel        string        `json:"level" yaml:"level" toml:"level"`                                  // 日志等级
+	Tags            string        `json:"tags" yaml:"tags" toml:"tags"`                                     // 日志Tag，格式如 "db:debug,net:warn,cache"，带等级的tag使用该等级，其他使用LogLevel
+	MaxDays         int           `json:"max_days" yaml:"max_days" toml:"max_days"`                         // 日志文件保留日期
+	RotateMode      string        `json:"rotate_mode" yaml:"rotate_mode" toml:"rotate_mode"`                // 日志文件轮转模式，daily（缺省）按天、hourly按小时、interval按RotateInterval间隔、size按大小、daily_size按天或按大小先到先轮转、none不轮转
+	RotateInterval  time.Duration `json:"rotate_interval" yaml:"rotate_interval" toml:"rotate_interval"`    // 轮转间隔，interval模式下生效，缺省为1小时
+	MaxSize         int           `json:"max_size" yaml:"max_size" toml:"max_size"`                         // 单个日志文件最大尺寸(MB)，按大小轮转时生效，缺省为100
+	MaxBackups      int           `json:"max_backups" yaml:"max_backups" toml:"max_backups"`                // 最多保留的备份文件个数，缺省为不限制
+	MaxTotalSize    int           `json:"max_total_size" yaml:"max_total_size" toml:"max_total_size"`       // 日志文件及所有备份的总大小上限(MB)，超出时从最旧的备份开始删除，缺省为不限制，rotate_mode为none时不生效
+	MaxAge          time.Duration `json:"max_age" yaml:"max_age" toml:"max_age"`                            // 备份文件保留时长，配置后优先于MaxDays
+	ReopenSignal    bool          `json:"reopen_signal" yaml:"reopen_signal" toml:"reopen_signal"`          // 收到SIGHUP或SIGUSR1信号时重新打开日志文件，配合logrotate使用
+	LevelFiles      string        `json:"level_files" yaml:"level_files" toml:"level_files"`                // 按等级分出的日志文件，格式如 "warn:error"，等级达到warn的日志同时写入 app.error.log，各文件独立轮转和清理，仅对LogPath的文件输出生效
+	Compress        string        `json:"compress" yaml:"compress" toml:"compress"`                         // 备份文件压缩算法，gzip或通过RegisterCompressor注册的算法（如zstd），缺省为不压缩
+	DisableLogColor bool          `json:"disable_color" yaml:"disable_color" toml:"disable_color"`          // 终端输出是否显示颜色
+	DisableCaller   bool          `json:"disable_caller" yaml:"disable_caller" toml:"disable_caller"`       // 是否打印调用文件
+	CallerSkip      int           `json:"caller_skip" yaml:"caller_skip" toml:"caller_skip"`                // 打印文件级
+	CallerMode      string        `json:"caller_mode" yaml:"caller_mode" toml:"caller_mode"`                // 调用位置格式，short（缺省）文件名、relative相对模块根目录的路径、full完整路径、function文件名及函数名
+	StackLevel      string        `json:"stack_level" yaml:"stack_level" toml:"stack_level"`                // 日志等级达到该等级时附带调用栈，如error，缺省为不记录
+	Encoding        string        `json:"encoding" yaml:"encoding" toml:"encoding"`                         // 日志编码格式，text（缺省）或json
+	Layout          string        `json:"layout" yaml:"layout" toml:"layout"`                               // 文本格式模板，缺省为 "{time} {level} {caller} {tag} {msg}"，可用占位符还有{fields}，没有{fields}时字段跟在{msg}之后
+	TimeFormat      string        `json:"time_format" yaml:"time_format" toml:"time_format"`                // 时间格式，Go时间格式或 rfc3339、rfc3339ms、unix、unixms，缺省为 "2006-01-02 15:04:05.000"
+	TimeZone        string        `json:"time_zone" yaml:"time_zone" toml:"time_zone"`                      // 时区，local（缺省）、utc或时区名称如 Asia/Shanghai
+	LevelFormat     string        `json:"level_format" yaml:"level_format" toml:"level_format"`             // 等级名称格式，short（缺省）如[I]，full如[INFO]
+	Async           bool          `json:"async" yaml:"async" toml:"async"`                                  // 是否异步写日志，程序退出前需调用Close或Flush
+	AsyncQueueSize  int           `json:"async_queue_size" yaml:"async_queue_size" toml:"async_queue_size"` // 异步队列容量(日志条数)，缺省为8192
+	AsyncOverflow   string        `json:"async_overflow" yaml:"async_overflow" toml:"async_overflow"`       // 异步队列满时的处理策略，block（缺省）阻塞、drop丢弃、drop_low丢弃Trace和Debug日志、其他等级先移除队列中的Trace和Debug日志再阻塞
+
+	SampleInitial    int           `json:"sample_initial" yaml:"sample_initial" toml:"sample_initial"`          // 重复日志采样，每个周期内相同等级、tag、模板的日志前N条全部输出，缺省为0不采样
+	SampleThereafter int           `json:"sample_thereafter" yaml:"sample_thereafter" toml:"sample_thereafter"` // 超出前N条后每M条输出1条，为0时全部丢弃，周期结束时输出被丢弃条数的统计
+	SampleInterval   time.Duration `json:"sample_interval" yaml:"sample_interval" toml:"sample_interval"`       // 采样周期，缺省为1秒
+
+	CollapseRepeated bool          `json:"collapse_repeated" yaml:"collapse_repeated" toml:"collapse_repeated"` // 是否合并连续重复的日志，重复日志在出现不同日志或超时后输出为 "last message repeated N times"
+	CollapseTimeout  time.Duration `json:"collapse_timeout" yaml:"collapse_timeout" toml:"collapse_timeout"`    // 重复日志最长缓存时间，缺省为30秒
+
+	RedactFields string `json:"redact_fields" yaml:"redact_fields" toml:"redact_fields"` // 需要脱敏的字段名，逗号分隔，如 "password,token,secret"，字段名包含其中之一（不区分大小写）时值替换为******
+	RedactRules  string `json:"redact_rules" yaml:"redact_rules" toml:"redact_rules"`    // 日志内容和字段值的脱敏规则，逗号分隔，内置 mobile、idcard、bankcard、email，可通过RegisterRedactRule注册，按配置顺序处理
+
+	Outputs []OutputOption `json:"outputs" yaml:"outputs" toml:"outputs"` // 附加输出，每个输出拥有独立的等级、Tag过滤和颜色设置，轮转和异步配置与上面相同
+
+	Writer io.Writer `json:"-" yaml:"-" toml:"-"` // 终端输出的写入目标，缺省为os.Stdout，可替换为os.Stderr或测试中的缓存
+}
+
+// OutputOption 附加输出配置
+type OutputOption struct {
+	LogPath         string `json:"path" yaml:"path" toml:"path"`                            // 日志输出文件， console为终端输出
+	LogLevel        string `json:"level" yaml:"level" toml:"level"`                         // 该输出的最低日志等级，缺省为不限制
+	Tags            string `json:"tags" yaml:"tags" toml:"tags"`                            // 该输出的Tag过滤，格式与Option.Tags相同，缺省为不过滤
+	DisableLogColor bool   `json:"disable_color" yaml:"disable_color" toml:"disable_color"` // 终端输出是否显示颜色
+	Encoding        string `json:"encoding" yaml:"encoding" toml:"encoding"`                // 日志编码格式，缺省与Option.Encoding相同
+}
+
+func newEncoderConfig(opt Option) encoderConfig {
+	return encoderConfig{
+		Encoding:    opt.Encoding,
+		Layout:      opt.Layout,
+		TimeFormat:  opt.TimeFormat,
+		TimeZone:    opt.TimeZone,
+		LevelFormat: opt.LevelFormat,
+	}
 }
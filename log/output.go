@@ -0,0 +1,203 @@
+package log
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// output 一个日志输出，拥有独立的最低等级、Tag过滤和编码格式
+type output struct {
+	mu       sync.Mutex
+	w        io.Writer
+	key      outputKey // 创建写入器的配置
+	enc      encoder   // 为nil时使用日志对象的编码格式
+	colorful bool      // 按等级着色，仅终端输出生效
+	level    Level     // 最低输出等级，为0时不限制
+	tags     tags      // Tag过滤及各Tag的最低等级，为空时不过滤
+}
+
+// outputKey 创建输出写入器用到的配置，修改配置时相同key的输出复用已打开的写入器
+type outputKey struct {
+	path           string    // 为空时是终端输出
+	writer         io.Writer // 终端输出的写入目标
+	rotateMode     string
+	maxDays        int
+	maxSize        int
+	maxBackups     int
+	maxTotalSize   int
+	interval       time.Duration
+	maxAge         time.Duration
+	compress       string
+	levelFiles     string // 按等级分文件的配置，仅主输出使用
+	async          bool
+	asyncQueueSize int
+	asyncOverflow  string
+}
+
+func newOutputKey(path string, opt Option) outputKey {
+	key := outputKey{
+		async:          opt.Async,
+		asyncQueueSize: opt.AsyncQueueSize,
+		asyncOverflow:  opt.AsyncOverflow,
+	}
+	if path == "" || path == "console" {
+		key.writer = opt.Writer
+		if key.writer != nil && !reflect.TypeOf(key.writer).Comparable() {
+			// 不可比较的写入器不能作为map的key，包装为指针，每次都创建新的输出
+			key.writer = &struct{ io.Writer }{opt.Writer}
+		}
+		return key
+	}
+
+	key.path = path
+	key.rotateMode = opt.RotateMode
+	key.maxDays = opt.MaxDays
+	key.maxSize = opt.MaxSize
+	key.maxBackups = opt.MaxBackups
+	key.maxTotalSize = opt.MaxTotalSize
+	key.interval = opt.RotateInterval
+	key.maxAge = opt.MaxAge
+	key.compress = opt.Compress
+	return key
+}
+
+// newOutput 按路径创建输出，path为空或console时为终端输出，否则为轮转文件输出。
+// 轮转和异步配置使用key中的设置
+func newOutput(key outputKey, colorful bool) *output {
+	var w io.Writer
+	console := key.path == ""
+	if console {
+		w = newConsoleWriter(key.writer)
+	} else {
+		mode, err := parseRotateMode(key.rotateMode)
+		if err != nil {
+			mode = rotateFileModeDaily
+		}
+		levelFiles, _ := parseLevelFiles(key.levelFiles)
+		writer := newRotateFileWriter(rotateFileConfig{
+			FileName:   key.path,
+			Mode:       mode,
+			MaxDays:    key.maxDays,
+			MaxSize:    key.maxSize,
+			MaxBackups: key.maxBackups,
+			MaxTotal:   key.maxTotalSize,
+			Interval:   key.interval,
+			MaxAge:     key.maxAge,
+			Compress:   key.compress,
+			LevelFiles: levelFiles,
+		})
+		writer.Init()
+		w = writer
+	}
+
+	if key.async {
+		policy, err := parseOverflowPolicy(key.asyncOverflow)
+		if err != nil {
+			policy = overflowBlock
+		}
+		w = newAsyncWriter(w, asyncConfig{
+			QueueSize: key.asyncQueueSize,
+			Overflow:  policy,
+		})
+	}
+
+	return &output{w: w, key: key, colorful: console && colorful}
+}
+
+// newOutputs 根据配置创建所有输出。LogPath为主输出，Outputs为附加输出；
+// 只配置了Outputs时不再创建缺省的终端输出。
+// old中写入器配置相同的输出复用其写入器，不再使用的旧输出在unused中返回，由调用方关闭
+func newOutputs(opt Option, old []*output) (outputs, unused []*output) {
+	writers := make(map[outputKey][]io.Writer)
+	for _, o := range old {
+		writers[o.key] = append(writers[o.key], o.w)
+	}
+	open := func(key outputKey, colorful bool) *output {
+		if ws := writers[key]; len(ws) > 0 {
+			writers[key] = ws[1:]
+			return &output{w: ws[0], key: key, colorful: key.path == "" && colorful}
+		}
+		return newOutput(key, colorful)
+	}
+
+	if opt.LogPath != "" || len(opt.Outputs) == 0 {
+		key := newOutputKey(opt.LogPath, opt)
+		if key.path != "" {
+			key.levelFiles = opt.LevelFiles
+		}
+		outputs = append(outputs, open(key, !opt.DisableLogColor))
+	}
+
+	for _, oo := range opt.Outputs {
+		o := open(newOutputKey(oo.LogPath, opt), !oo.DisableLogColor)
+		if oo.LogLevel != "" {
+			level, err := parseLevel(oo.LogLevel)
+			if err == nil {
+				o.level = level
+			}
+		}
+		if oo.Encoding != "" {
+			cfg := newEncoderConfig(opt)
+			cfg.Encoding = oo.Encoding
+			enc, err := newEncoder(cfg)
+			if err == nil {
+				o.enc = enc
+			}
+		}
+		o.tags, _ = parseTags(oo.Tags)
+		outputs = append(outputs, o)
+	}
+
+	for _, o := range old {
+		if ws := writers[o.key]; len(ws) > 0 && ws[0] == o.w {
+			writers[o.key] = ws[1:]
+			unused = append(unused, o)
+		}
+	}
+	return outputs, unused
+}
+
+// enabled 判断日志是否需要写入该输出
+func (o *output) enabled(e *Entry) bool {
+	return o.tags.Enabled(e.Tag, e.Level, o.level)
+}
+
+func (o *output) write(p []byte, level Level) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if lw, ok := o.w.(writer); ok {
+		_, _ = lw.WriteLog(p, level)
+		return
+	}
+	_, _ = o.w.Write(p)
+}
+
+func (o *output) flush() error {
+	if f, ok := o.w.(flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (o *output) sync() error {
+	if s, ok := o.w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+func (o *output) reopen() error {
+	if r, ok := o.w.(reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+func (o *output) close() {
+	if lw, ok := o.w.(writer); ok {
+		lw.CloseLog()
+	}
+}
@@ -0,0 +1,227 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zngw/golib/ringbuffer"
+)
+
+var errWriterClosed = errors.New("log writer is closed")
+
+// defaultAsyncQueueSize 异步队列缺省容量(日志条数)
+var defaultAsyncQueueSize = 8192
+
+type overflowPolicy string
+
+const (
+	overflowBlock   overflowPolicy = "block"    // 队列满时阻塞等待
+	overflowDrop    overflowPolicy = "drop"     // 队列满时丢弃新日志
+	overflowDropLow overflowPolicy = "drop_low" // 队列满时丢弃Trace和Debug日志，其他等级先移除队列中的低等级日志，仍无空间时阻塞等待
+)
+
+func parseOverflowPolicy(text string) (overflowPolicy, error) {
+	switch strings.ToLower(text) {
+	case "", string(overflowBlock):
+		return overflowBlock, nil
+	case string(overflowDrop):
+		return overflowDrop, nil
+	case string(overflowDropLow):
+		return overflowDropLow, nil
+	default:
+		return overflowBlock, fmt.Errorf("unrecognized overflow policy: %q", text)
+	}
+}
+
+var _ io.Writer = (*asyncWriter)(nil)
+
+type asyncConfig struct {
+	QueueSize int
+	Overflow  overflowPolicy
+}
+
+type asyncEntry struct {
+	p     []byte
+	level Level
+}
+
+// asyncWriter 将日志放入有界队列，由后台协程写入下层输出
+type asyncWriter struct {
+	cfg asyncConfig
+	w   io.Writer
+
+	mu      sync.Mutex
+	cond    *sync.Cond // 队列或写入状态变化时广播
+	queue   *ringbuffer.RingBuffer[asyncEntry]
+	low     int  // 队列中Trace和Debug日志的条数
+	writing bool // 后台协程正在写入
+	closed  bool
+	done    chan struct{}
+
+	dropped atomic.Int64 // 因队列满被丢弃的日志条数
+}
+
+func newAsyncWriter(w io.Writer, cfg asyncConfig) *asyncWriter {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = defaultAsyncQueueSize
+	}
+	queue, _ := ringbuffer.NewRingBuffer[asyncEntry](256)
+	aw := &asyncWriter{
+		cfg:   cfg,
+		w:     w,
+		queue: queue,
+		done:  make(chan struct{}),
+	}
+	aw.cond = sync.NewCond(&aw.mu)
+	go aw.run()
+	return aw
+}
+
+func (aw *asyncWriter) Write(p []byte) (n int, err error) {
+	// io.Writer 约定不能持有p，复制一份放入队列
+	return aw.WriteLog(append([]byte(nil), p...), LevelInfo)
+}
+
+// WriteLog 将日志放入队列，p在写入完成前不能被修改
+func (aw *asyncWriter) WriteLog(p []byte, level Level) (int, error) {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+
+	for !aw.closed && aw.queue.Len() >= aw.cfg.QueueSize {
+		if aw.cfg.Overflow == overflowDropLow && level >= LevelInfo && aw.low > 0 {
+			aw.evictLowLocked()
+			continue
+		}
+		if aw.cfg.Overflow == overflowDrop || (aw.cfg.Overflow == overflowDropLow && level < LevelInfo) {
+			aw.dropped.Add(1)
+			return len(p), nil
+		}
+		aw.cond.Wait()
+	}
+	if aw.closed {
+		return 0, errWriterClosed
+	}
+
+	aw.queue.Write(asyncEntry{p: p, level: level})
+	if level < LevelInfo {
+		aw.low++
+	}
+	aw.cond.Broadcast()
+	return len(p), nil
+}
+
+// evictLowLocked 移除队列中所有Trace和Debug日志，为更高等级的日志腾出空间，其余日志保持原有顺序
+func (aw *asyncWriter) evictLowLocked() {
+	for n := aw.queue.Len(); n > 0; n-- {
+		e := aw.queue.Pop()
+		if e.level < LevelInfo {
+			aw.dropped.Add(1)
+			continue
+		}
+		aw.queue.Write(e)
+	}
+	aw.low = 0
+}
+
+// run 后台协程，批量取出队列中的日志写入下层输出
+func (aw *asyncWriter) run() {
+	defer close(aw.done)
+
+	batch := make([]asyncEntry, 0, 256)
+	for {
+		aw.mu.Lock()
+		for aw.queue.IsEmpty() && !aw.closed {
+			aw.cond.Wait()
+		}
+		if aw.queue.IsEmpty() && aw.closed {
+			aw.mu.Unlock()
+			return
+		}
+		for !aw.queue.IsEmpty() && len(batch) < cap(batch) {
+			e := aw.queue.Pop()
+			if e.level < LevelInfo {
+				aw.low--
+			}
+			batch = append(batch, e)
+		}
+		if aw.queue.IsEmpty() {
+			aw.queue.Reset()
+		}
+		aw.writing = true
+		aw.cond.Broadcast()
+		aw.mu.Unlock()
+
+		for _, e := range batch {
+			if lw, ok := aw.w.(writer); ok {
+				_, _ = lw.WriteLog(e.p, e.level)
+			} else {
+				_, _ = aw.w.Write(e.p)
+			}
+		}
+		clear(batch)
+		batch = batch[:0]
+
+		aw.mu.Lock()
+		aw.writing = false
+		aw.cond.Broadcast()
+		aw.mu.Unlock()
+	}
+}
+
+// Flush 等待队列中的日志全部写入下层输出
+func (aw *asyncWriter) Flush() error {
+	aw.mu.Lock()
+	defer aw.mu.Unlock()
+	for !aw.queue.IsEmpty() || aw.writing {
+		aw.cond.Wait()
+	}
+	return nil
+}
+
+// Sync 写入队列中的日志，并将下层输出同步到磁盘
+func (aw *asyncWriter) Sync() error {
+	if err := aw.Flush(); err != nil {
+		return err
+	}
+	if s, ok := aw.w.(syncer); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Reopen 写入队列中的日志后重新打开下层的文件输出
+func (aw *asyncWriter) Reopen() error {
+	if err := aw.Flush(); err != nil {
+		return err
+	}
+	if r, ok := aw.w.(reopener); ok {
+		return r.Reopen()
+	}
+	return nil
+}
+
+// Dropped 因队列满被丢弃的日志条数
+func (aw *asyncWriter) Dropped() int64 {
+	return aw.dropped.Load()
+}
+
+// CloseLog 写完队列中的日志后关闭下层输出
+func (aw *asyncWriter) CloseLog() {
+	aw.mu.Lock()
+	if aw.closed {
+		aw.mu.Unlock()
+		return
+	}
+	aw.closed = true
+	aw.cond.Broadcast()
+	aw.mu.Unlock()
+
+	<-aw.done
+	if lw, ok := aw.w.(writer); ok {
+		lw.CloseLog()
+	}
+}
@@ -0,0 +1,109 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingWriter 持有hold时写入阻塞，使日志在异步队列中堆积
+type blockingWriter struct {
+	hold sync.Mutex
+
+	mu    sync.Mutex
+	lines []string
+}
+
+func (w *blockingWriter) Write(p []byte) (int, error) {
+	w.hold.Lock()
+	w.hold.Unlock()
+
+	w.mu.Lock()
+	w.lines = append(w.lines, string(p))
+	w.mu.Unlock()
+	return len(p), nil
+}
+
+func (w *blockingWriter) take() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	lines := w.lines
+	w.lines = nil
+	return lines
+}
+
+// 队列超过一个cell(256条)后取空再写入，日志不能丢失、重复或乱序
+func TestAsyncWriterRefillAfterDrain(t *testing.T) {
+	w := &blockingWriter{}
+	aw := newAsyncWriter(w, asyncConfig{QueueSize: 4096})
+	defer aw.CloseLog()
+
+	next := 0
+	for round := 0; round < 4; round++ {
+		start := next
+		w.hold.Lock()
+		for i := 0; i < 1000; i++ {
+			if _, err := aw.Write([]byte(fmt.Sprint(next))); err != nil {
+				t.Fatalf("round %d: write: %v", round, err)
+			}
+			next++
+		}
+		w.hold.Unlock()
+		_ = aw.Flush()
+
+		lines := w.take()
+		if len(lines) != next-start {
+			t.Fatalf("round %d: got %d entries, want %d", round, len(lines), next-start)
+		}
+		for i, line := range lines {
+			if want := fmt.Sprint(start + i); line != want {
+				t.Fatalf("round %d: entry %d is %q, want %q", round, i, line, want)
+			}
+		}
+	}
+}
+
+// drop_low策略下队列被Debug日志占满时，Error日志移除队列中的Debug日志后写入，不阻塞
+func TestAsyncWriterDropLowEvictsQueued(t *testing.T) {
+	w := &blockingWriter{}
+	aw := newAsyncWriter(w, asyncConfig{QueueSize: 8, Overflow: overflowDropLow})
+	defer aw.CloseLog()
+
+	// 第一条日志被后台协程取出后阻塞在下层输出，之后的日志留在队列中
+	w.hold.Lock()
+	_, _ = aw.WriteLog([]byte("first"), LevelInfo)
+	for {
+		aw.mu.Lock()
+		writing := aw.writing
+		aw.mu.Unlock()
+		if writing {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for i := 0; i < 8; i++ {
+		_, _ = aw.WriteLog([]byte(fmt.Sprint("debug", i)), LevelDebug)
+	}
+
+	written := make(chan struct{})
+	go func() {
+		_, _ = aw.WriteLog([]byte("error"), LevelError)
+		close(written)
+	}()
+	select {
+	case <-written:
+	case <-time.After(time.Second):
+		w.hold.Unlock()
+		t.Fatal("error entry blocked on a queue full of debug entries")
+	}
+	w.hold.Unlock()
+	_ = aw.Flush()
+
+	if lines := w.take(); fmt.Sprint(lines) != "[first error]" {
+		t.Fatalf("got %v, want [first error]", lines)
+	}
+	if n := aw.Dropped(); n != 8 {
+		t.Fatalf("dropped %d entries, want 8", n)
+	}
+}
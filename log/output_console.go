@@ -3,7 +3,6 @@ package log
 import (
 	"io"
 	"os"
-	"strings"
 )
 
 // brush is a color join function
@@ -28,6 +27,8 @@ var colors = []brush{
 	newBrush("1;34"), // Info 				Blue
 	newBrush("1;33"), // Warn               Yellow
 	newBrush("1;31"), // Error              Red
+	newBrush("1;35"), // Panic              Magenta
+	newBrush("1;41"), // Fatal              Red Background
 }
 
 func colorBrushByLevel(level Level) brush {
@@ -42,6 +43,10 @@ func colorBrushByLevel(level Level) brush {
 		return colors[3]
 	case LevelError:
 		return colors[4]
+	case LevelPanic:
+		return colors[5]
+	case LevelFatal:
+		return colors[6]
 	default:
 		return colors[2]
 	}
@@ -49,19 +54,18 @@ func colorBrushByLevel(level Level) brush {
 
 var _ io.Writer = (*consoleWriter)(nil)
 
-type consoleConfig struct {
-	Colorful bool
-}
-
+// consoleWriter 终端输出，颜色由编码时按等级添加
 type consoleWriter struct {
-	cfg consoleConfig
-	w   io.Writer
+	w io.Writer
 }
 
-func newConsoleWriter(cfg consoleConfig) io.Writer {
+// newConsoleWriter 创建终端输出，w为nil时写入os.Stdout
+func newConsoleWriter(w io.Writer) io.Writer {
+	if w == nil {
+		w = os.Stdout
+	}
 	return &consoleWriter{
-		cfg: cfg,
-		w:   os.Stdout,
+		w: w,
 	}
 }
 
@@ -69,11 +73,7 @@ func (cw *consoleWriter) Write(p []byte) (n int, err error) {
 	return cw.w.Write(p)
 }
 
-func (cw *consoleWriter) WriteLog(p []byte, level Level) (n int, err error) {
-	if cw.cfg.Colorful {
-		p = []byte(strings.Replace(string(p), level.LogPrefix(), colorBrushByLevel(level)(level.LogPrefix()), 1))
-	}
-
+func (cw *consoleWriter) WriteLog(p []byte, _ Level) (n int, err error) {
 	return cw.w.Write(p)
 }
 
@@ -0,0 +1,89 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// levelFileConfig 按等级分出的日志文件，等级达到Level的日志同时写入该文件
+type levelFileConfig struct {
+	Level  Level
+	Suffix string // 插入到文件名和扩展名之间，如 error 对应 app.error.log
+}
+
+// parseLevelFiles 解析按等级分文件的配置，格式为 "warn:error,debug:debug"。
+// 无法识别的项跳过并返回错误
+func parseLevelFiles(str string) ([]levelFileConfig, error) {
+	var errs []error
+	var files []levelFileConfig
+	for _, v := range strings.Split(str, ",") {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		levelText, suffix, ok := strings.Cut(v, ":")
+		suffix = strings.TrimSpace(suffix)
+		if !ok || suffix == "" {
+			errs = append(errs, fmt.Errorf("level file %q: expected \"level:suffix\"", v))
+			continue
+		}
+		if strings.ContainsAny(suffix, `/\`) {
+			errs = append(errs, fmt.Errorf("level file %q: suffix must not contain path separators", v))
+			continue
+		}
+		level, err := parseLevel(strings.TrimSpace(levelText))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("level file %q: %w", v, err))
+			continue
+		}
+		files = append(files, levelFileConfig{Level: level, Suffix: suffix})
+	}
+	return files, errors.Join(errs...)
+}
+
+// levelFileName 在文件名和扩展名之间插入后缀，如 logs/app.log 加 error 为 logs/app.error.log
+func levelFileName(name, suffix string) string {
+	ext := filepath.Ext(name)
+	return name[:len(name)-len(ext)] + "." + suffix + ext
+}
+
+// levelFile 按等级分出的日志文件，与主日志文件各自轮转和清理
+type levelFile struct {
+	level Level
+	fw    *rotateFileWriter
+}
+
+func newLevelFiles(cfg rotateFileConfig) []levelFile {
+	files := make([]levelFile, 0, len(cfg.LevelFiles))
+	for _, lf := range cfg.LevelFiles {
+		c := cfg
+		c.FileName = levelFileName(cfg.FileName, lf.Suffix)
+		c.LevelFiles = nil
+		files = append(files, levelFile{level: lf.Level, fw: newRotateFileWriter(c)})
+	}
+	return files
+}
+
+// writeLevelFiles 将日志写入等级匹配的分文件
+func (fw *rotateFileWriter) writeLevelFiles(p []byte, level Level) error {
+	var errs []error
+	for _, lf := range fw.levelFiles {
+		if level >= lf.level {
+			_, err := lf.fw.WriteLog(p, level)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// eachLevelFile 对所有分文件执行fn
+func (fw *rotateFileWriter) eachLevelFile(fn func(w *rotateFileWriter) error) error {
+	var errs []error
+	for _, lf := range fw.levelFiles {
+		errs = append(errs, fn(lf.fw))
+	}
+	return errors.Join(errs...)
+}
@@ -14,49 +14,108 @@ import (
 
 var defaultLogFileName = "file.log"
 
+// defaultMaxSize 按大小轮转时，未配置MaxSize的缺省值(MB)
+var defaultMaxSize = 100
+
+// quotaCheckInterval 配置了总大小上限时定期检查的间隔
+var quotaCheckInterval = time.Minute
+
 type rotateFileMode string
 
 const (
-	rotateFileModeNone  rotateFileMode = ""
-	rotateFileModeDaily rotateFileMode = "daily"
+	rotateFileModeNone      rotateFileMode = "" // 不轮转，由logrotate等外部工具负责
+	rotateFileModeDaily     rotateFileMode = "daily"
+	rotateFileModeHourly    rotateFileMode = "hourly"
+	rotateFileModeInterval  rotateFileMode = "interval" // 按自定义时间间隔轮转
+	rotateFileModeSize      rotateFileMode = "size"
+	rotateFileModeDailySize rotateFileMode = "daily_size" // 按天或按大小，先到先轮转
 )
 
+func parseRotateMode(text string) (rotateFileMode, error) {
+	switch strings.ToLower(text) {
+	case "", string(rotateFileModeDaily):
+		return rotateFileModeDaily, nil
+	case "none":
+		return rotateFileModeNone, nil
+	case string(rotateFileModeHourly):
+		return rotateFileModeHourly, nil
+	case string(rotateFileModeInterval):
+		return rotateFileModeInterval, nil
+	case string(rotateFileModeSize):
+		return rotateFileModeSize, nil
+	case string(rotateFileModeDailySize):
+		return rotateFileModeDailySize, nil
+	default:
+		return rotateFileModeNone, fmt.Errorf("unrecognized rotate mode: %q", text)
+	}
+}
+
 var _ io.WriteCloser = (*rotateFileWriter)(nil)
 
 type rotateFileConfig struct {
-	FileName string
-	Mode     rotateFileMode
-	MaxDays  int
+	FileName   string
+	Mode       rotateFileMode
+	MaxDays    int
+	MaxSize    int               // 单个日志文件最大尺寸(MB)，仅在按大小轮转时生效
+	MaxBackups int               // 最多保留的备份文件个数
+	MaxTotal   int               // 日志文件及所有备份的总大小上限(MB)，超出时删除最旧的备份
+	Interval   time.Duration     // 轮转间隔，仅在按时间间隔轮转时生效
+	MaxAge     time.Duration     // 备份文件保留时长，优先于MaxDays
+	Compress   string            // 备份文件压缩算法，gzip或通过RegisterCompressor注册的算法，为空不压缩
+	LevelFiles []levelFileConfig // 按等级分出的日志文件，使用相同的轮转和清理配置
 }
 
 type rotateFileWriter struct {
 	cfg rotateFileConfig
 
-	mu   sync.Mutex
-	file *os.File
-	done chan struct{}
+	mu     sync.Mutex
+	file   *os.File
+	size   int64 // 当前日志文件大小
+	done   chan struct{}
+	millCh chan struct{} // 通知后台协程压缩和清理备份文件
+
+	levelFiles []levelFile // 按等级分出的日志文件
 }
 
 func newRotateFileWriter(cfg rotateFileConfig) *rotateFileWriter {
 	if cfg.FileName == "" {
 		cfg.FileName = defaultLogFileName
 	}
+	if cfg.MaxSize <= 0 {
+		cfg.MaxSize = defaultMaxSize
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
 	fw := &rotateFileWriter{
-		cfg:  cfg,
-		done: make(chan struct{}),
+		cfg:        cfg,
+		done:       make(chan struct{}),
+		millCh:     make(chan struct{}, 1),
+		levelFiles: newLevelFiles(cfg),
 	}
 	return fw
 }
 
 func (fw *rotateFileWriter) Init() {
+	for _, lf := range fw.levelFiles {
+		lf.fw.Init()
+	}
+
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
 	if fw.done != nil {
 		close(fw.done)
 	}
-	fw.done = make(chan struct{})
-	if fw.cfg.Mode == rotateFileModeDaily {
-		go fw.dailyRotate()
+	// 在锁内取得done传给后台协程，协程启动前Close将fw.done置为nil时仍能退出
+	done := make(chan struct{})
+	fw.done = done
+	if fw.timedRotateEnabled() {
+		go fw.timedRotate(done)
+	}
+	go fw.millRun(done)
+	if fw.cfg.Compress != "" || fw.cfg.MaxTotal > 0 {
+		// 压缩上次运行时遗留的未压缩备份，检查备份总大小
+		fw.mill()
 	}
 }
 
@@ -64,7 +123,8 @@ func (fw *rotateFileWriter) Write(p []byte) (n int, err error) {
 	return fw.WriteLog(p, LevelInfo)
 }
 
-func (fw *rotateFileWriter) WriteLog(p []byte, _ Level) (int, error) {
+// WriteLog 写入日志文件，等级达到分文件配置的日志同时写入对应的分文件
+func (fw *rotateFileWriter) WriteLog(p []byte, level Level) (int, error) {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
 
@@ -74,10 +134,28 @@ func (fw *rotateFileWriter) WriteLog(p []byte, _ Level) (int, error) {
 		}
 	}
 
+	if fw.sizeExceeded(len(p)) {
+		if err := fw.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
 	n, err := fw.file.Write(p)
+	fw.size += int64(n)
+	if len(fw.levelFiles) > 0 {
+		err = errors.Join(err, fw.writeLevelFiles(p, level))
+	}
 	return n, err
 }
 
+// sizeExceeded 判断写入n字节后是否超出单个文件的最大尺寸
+func (fw *rotateFileWriter) sizeExceeded(n int) bool {
+	if fw.cfg.Mode != rotateFileModeSize && fw.cfg.Mode != rotateFileModeDailySize {
+		return false
+	}
+	return fw.size > 0 && fw.size+int64(n) > int64(fw.cfg.MaxSize)*1024*1024
+}
+
 func (fw *rotateFileWriter) CloseLog() {
 	err := fw.Close()
 	if err != nil {
@@ -85,25 +163,45 @@ func (fw *rotateFileWriter) CloseLog() {
 	}
 }
 
-func (fw *rotateFileWriter) Rotate() error {
+// Reopen 关闭并重新打开日志文件，用于logrotate等外部工具重命名日志文件后写入新文件
+func (fw *rotateFileWriter) Reopen() error {
+	return errors.Join(fw.reopen(), fw.eachLevelFile((*rotateFileWriter).Reopen))
+}
+
+func (fw *rotateFileWriter) reopen() error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
-	return fw.rotate()
+	if err := fw.closeFile(); err != nil {
+		return err
+	}
+	return fw.openExistingOrNew()
+}
+
+func (fw *rotateFileWriter) Rotate() error {
+	fw.mu.Lock()
+	err := fw.rotate()
+	fw.mu.Unlock()
+	return errors.Join(err, fw.eachLevelFile((*rotateFileWriter).Rotate))
 }
 
 func (fw *rotateFileWriter) rotate() error {
+	return fw.rotateAs(time.Now(), backupTimeFormat)
+}
+
+// rotateAs 轮转日志文件，备份文件名使用layout格式化的时间t
+func (fw *rotateFileWriter) rotateAs(t time.Time, layout string) error {
 	if err := fw.closeFile(); err != nil {
 		return err
 	}
-	if err := fw.openNew(); err != nil {
+	if err := fw.openNewAs(t, layout); err != nil {
 		return err
 	}
-	_ = fw.clearFiles()
+	fw.mill()
 	return nil
 }
 
 func (fw *rotateFileWriter) openExistingOrNew() error {
-	_, err := os.Stat(fw.cfg.FileName)
+	info, err := os.Stat(fw.cfg.FileName)
 	if os.IsNotExist(err) {
 		return fw.openNew()
 	}
@@ -116,10 +214,15 @@ func (fw *rotateFileWriter) openExistingOrNew() error {
 		return fw.openNew()
 	}
 	fw.file = file
+	fw.size = info.Size()
 	return nil
 }
 
 func (fw *rotateFileWriter) openNew() error {
+	return fw.openNewAs(time.Now(), backupTimeFormat)
+}
+
+func (fw *rotateFileWriter) openNewAs(t time.Time, layout string) error {
 	err := os.MkdirAll(fw.dir(), 0o755)
 	if err != nil {
 		return fmt.Errorf("mkdir directories [%s] for new logfile error: %s", fw.dir(), err)
@@ -129,7 +232,7 @@ func (fw *rotateFileWriter) openNew() error {
 	info, err := os.Stat(fw.cfg.FileName)
 	if err == nil {
 		mode = info.Mode()
-		newName := fw.backupName(fw.cfg.FileName, time.Now())
+		newName := fw.backupName(fw.cfg.FileName, t, layout)
 		if err := os.Rename(fw.cfg.FileName, newName); err != nil {
 			return fmt.Errorf("rename logfile error: %s", err)
 		}
@@ -140,21 +243,46 @@ func (fw *rotateFileWriter) openNew() error {
 		return fmt.Errorf("open new logfile error: %s", err)
 	}
 	fw.file = f
+	fw.size = 0
 	return nil
 }
 
 var backupTimeFormat = "20060102-150405"
 
-func (fw *rotateFileWriter) backupName(name string, t time.Time) string {
+// backupTimeFormatMilli 同一秒内多次轮转时使用的毫秒时间戳格式
+var backupTimeFormatMilli = "20060102-150405.000"
+
+// 按时间轮转时，备份文件名的时间精度与轮转间隔一致
+var (
+	backupTimeFormatDay    = "20060102"
+	backupTimeFormatHour   = "2006010215"
+	backupTimeFormatMinute = "20060102-1504"
+)
+
+// backupTimeFormats 解析备份文件名时依次尝试的时间格式
+var backupTimeFormats = []string{
+	backupTimeFormat,
+	backupTimeFormatMilli,
+	backupTimeFormatDay,
+	backupTimeFormatHour,
+	backupTimeFormatMinute,
+}
+
+func (fw *rotateFileWriter) backupName(name string, t time.Time, layout string) string {
 	dir := filepath.Dir(name)
 	filename := filepath.Base(name)
 	ext := filepath.Ext(filename)
 	prefix := filename[:len(filename)-len(ext)]
 
-	timestamp := t.Format(backupTimeFormat)
-	return filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, timestamp, ext))
+	newName := filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, t.Format(layout), ext))
+	if _, err := os.Stat(newName); err == nil {
+		// 同名备份已存在（如按大小轮转时同一秒内多次轮转），使用当前的毫秒时间戳避免覆盖
+		newName = filepath.Join(dir, fmt.Sprintf("%s.%s%s", prefix, time.Now().Format(backupTimeFormatMilli), ext))
+	}
+	return newName
 }
 
+// parseTimeFromBackupName 解析备份文件的轮转时间
 func (fw *rotateFileWriter) parseTimeFromBackupName(filename, prefix, ext string) (time.Time, error) {
 	if !strings.HasPrefix(filename, prefix) {
 		return time.Time{}, errors.New("missmatched prefix")
@@ -166,39 +294,161 @@ func (fw *rotateFileWriter) parseTimeFromBackupName(filename, prefix, ext string
 		return time.Time{}, errors.New("missmatched prefix and ext")
 	}
 	timestamp := filename[len(prefix) : len(filename)-len(ext)]
-	return time.ParseInLocation(backupTimeFormat, timestamp, time.Local)
+	for _, layout := range backupTimeFormats {
+		if t, err := time.ParseInLocation(layout, timestamp, time.Local); err == nil {
+			return fw.backupEndTime(t, layout), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized backup timestamp: %q", timestamp)
+}
+
+// backupEndTime 按时间轮转的备份以周期开始时间命名，排序和计算保留时长时使用周期的结束时间，
+// 与其他备份一样以轮转时间为准，否则同一时刻的按大小轮转的备份会排在其后，保留时长也会少一个周期
+func (fw *rotateFileWriter) backupEndTime(t time.Time, layout string) time.Time {
+	if _, end, l := fw.period(t); fw.timedRotateEnabled() && l == layout && layout != backupTimeFormat {
+		return end
+	}
+	switch layout {
+	case backupTimeFormatDay:
+		return t.AddDate(0, 0, 1)
+	case backupTimeFormatHour:
+		return t.Add(time.Hour)
+	case backupTimeFormatMinute:
+		return t.Add(time.Minute)
+	default:
+		return t
+	}
 }
 
 func (fw *rotateFileWriter) dir() string {
 	return filepath.Dir(fw.cfg.FileName)
 }
 
-func (fw *rotateFileWriter) dailyRotate() {
-	fw.mu.Lock()
-	doneCh := fw.done
-	fw.mu.Unlock()
+func (fw *rotateFileWriter) timedRotateEnabled() bool {
+	switch fw.cfg.Mode {
+	case rotateFileModeDaily, rotateFileModeDailySize, rotateFileModeHourly, rotateFileModeInterval:
+		return true
+	default:
+		return false
+	}
+}
+
+// period 返回now所在轮转周期的起止时间，以及备份文件名使用的时间格式
+func (fw *rotateFileWriter) period(now time.Time) (start, end time.Time, layout string) {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	nextMidnight := midnight.AddDate(0, 0, 1)
+
+	switch fw.cfg.Mode {
+	case rotateFileModeHourly:
+		start = now.Truncate(time.Hour)
+		return start, start.Add(time.Hour), backupTimeFormatHour
+	case rotateFileModeInterval:
+		interval := fw.cfg.Interval
+		if interval > 24*time.Hour {
+			start = now.Truncate(interval)
+			end = start.Add(interval)
+		} else {
+			// 一天以内的间隔从本地时间零点开始对齐，跨天时在零点截断
+			start = midnight.Add(now.Sub(midnight) / interval * interval)
+			end = start.Add(interval)
+			if end.After(nextMidnight) {
+				end = nextMidnight
+			}
+		}
+		return start, end, intervalTimeFormat(interval)
+	default:
+		return midnight, nextMidnight, backupTimeFormatDay
+	}
+}
+
+// intervalTimeFormat 返回与轮转间隔精度一致的时间格式
+func intervalTimeFormat(interval time.Duration) string {
+	switch {
+	case interval%(24*time.Hour) == 0:
+		return backupTimeFormatDay
+	case interval%time.Hour == 0:
+		return backupTimeFormatHour
+	case interval%time.Minute == 0:
+		return backupTimeFormatMinute
+	default:
+		return backupTimeFormat
+	}
+}
+
+// timedRotate 在每个轮转周期结束时轮转日志文件，备份文件名使用周期的开始时间，
+// 清理备份时按周期的结束时间计算，见backupEndTime
+func (fw *rotateFileWriter) timedRotate(done <-chan struct{}) {
 	for {
 		now := time.Now()
-		// Calculate the time difference until the next hour.
-		nextHour := now.Truncate(time.Hour).Add(time.Hour)
+		start, end, layout := fw.period(now)
 		select {
-		case <-time.After(nextHour.Sub(now)):
-		case <-doneCh:
+		case <-time.After(end.Sub(now)):
+		case <-done:
 			return
 		}
 
-		// Rotate the log file at 0 hour of the day.
-		if nextHour.Hour() == 0 {
-			_ = fw.Rotate()
-			// Ensure it's executed only once, even if the waiting period crosses midnight.
-			time.Sleep(time.Minute)
+		fw.mu.Lock()
+		_ = fw.rotateAs(start, layout)
+		fw.mu.Unlock()
+	}
+}
+
+// mill 通知后台协程处理备份文件，不阻塞写日志
+func (fw *rotateFileWriter) mill() {
+	select {
+	case fw.millCh <- struct{}{}:
+	default:
+	}
+}
+
+// millRun 后台压缩和清理备份文件。配置了总大小上限时还会定期检查，日志文件在两次轮转之间也会增长
+func (fw *rotateFileWriter) millRun(done <-chan struct{}) {
+	var tick <-chan time.Time
+	if fw.cfg.MaxTotal > 0 {
+		ticker := time.NewTicker(quotaCheckInterval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-fw.millCh:
+		case <-tick:
+		case <-done:
+			return
+		}
+
+		_ = fw.compressFiles()
+		_ = fw.clearFiles()
+	}
+}
+
+// compressFiles 压缩所有未压缩的备份文件
+func (fw *rotateFileWriter) compressFiles() error {
+	if fw.cfg.Compress == "" {
+		return nil
+	}
+	c, err := getCompressor(fw.cfg.Compress)
+	if err != nil {
+		return err
+	}
+
+	files, err := fw.oldLogFiles()
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.compressed {
+			continue
 		}
+		_ = compressFile(filepath.Join(fw.dir(), f.info.Name()), c)
 	}
+	return nil
 }
 
 type logFileInfo struct {
-	info os.FileInfo
-	t    time.Time
+	info       os.FileInfo
+	t          time.Time
+	compressed bool
 }
 
 func (fw *rotateFileWriter) oldLogFiles() ([]logFileInfo, error) {
@@ -221,8 +471,9 @@ func (fw *rotateFileWriter) oldLogFiles() ([]logFileInfo, error) {
 			continue
 		}
 
-		if t, err := fw.parseTimeFromBackupName(entry.Name(), prefix, ext); err == nil {
-			fileInfos = append(fileInfos, logFileInfo{info: info, t: t})
+		name, compressed := trimCompressExt(entry.Name())
+		if t, err := fw.parseTimeFromBackupName(name, prefix, ext); err == nil {
+			fileInfos = append(fileInfos, logFileInfo{info: info, t: t, compressed: compressed})
 			continue
 		}
 	}
@@ -237,7 +488,8 @@ func (fw *rotateFileWriter) clearFiles() error {
 	if fw.cfg.Mode == rotateFileModeNone {
 		return nil
 	}
-	if fw.cfg.Mode == rotateFileModeDaily && fw.cfg.MaxDays <= 0 {
+	maxAge := fw.maxAge()
+	if maxAge <= 0 && fw.cfg.MaxBackups <= 0 && fw.cfg.MaxTotal <= 0 {
 		return nil
 	}
 
@@ -247,13 +499,40 @@ func (fw *rotateFileWriter) clearFiles() error {
 	}
 
 	var toRemove []logFileInfo
-	if fw.cfg.Mode == rotateFileModeDaily {
-		cutoff := time.Now().Add(-time.Duration(fw.cfg.MaxDays) * time.Duration(24) * time.Hour).Add(5 * time.Millisecond)
+	if maxAge > 0 {
+		cutoff := time.Now().Add(-maxAge).Add(5 * time.Millisecond)
+		remaining := make([]logFileInfo, 0, len(files))
 		for _, f := range files {
 			if f.t.Before(cutoff) {
 				toRemove = append(toRemove, f)
+			} else {
+				remaining = append(remaining, f)
 			}
 		}
+		files = remaining
+	}
+
+	// files 按时间升序排列，超出备份个数时删除最旧的备份
+	if fw.cfg.MaxBackups > 0 && len(files) > fw.cfg.MaxBackups {
+		toRemove = append(toRemove, files[:len(files)-fw.cfg.MaxBackups]...)
+		files = files[len(files)-fw.cfg.MaxBackups:]
+	}
+
+	// 日志文件和备份的总大小超出上限时，从最旧的备份开始删除，日志文件本身不删除
+	if fw.cfg.MaxTotal > 0 {
+		var total int64
+		if info, err := os.Stat(fw.cfg.FileName); err == nil {
+			total = info.Size()
+		}
+		for _, f := range files {
+			total += f.info.Size()
+		}
+		limit := int64(fw.cfg.MaxTotal) * 1024 * 1024
+		for len(files) > 0 && total > limit {
+			total -= files[0].info.Size()
+			toRemove = append(toRemove, files[0])
+			files = files[1:]
+		}
 	}
 
 	for _, f := range toRemove {
@@ -262,7 +541,33 @@ func (fw *rotateFileWriter) clearFiles() error {
 	return nil
 }
 
+// maxAge 备份文件保留时长，MaxAge优先，未配置时使用MaxDays
+func (fw *rotateFileWriter) maxAge() time.Duration {
+	if fw.cfg.MaxAge > 0 {
+		return fw.cfg.MaxAge
+	}
+	return time.Duration(fw.cfg.MaxDays) * time.Duration(24) * time.Hour
+}
+
+// Sync 将已写入的日志同步到磁盘
+func (fw *rotateFileWriter) Sync() error {
+	return errors.Join(fw.sync(), fw.eachLevelFile((*rotateFileWriter).Sync))
+}
+
+func (fw *rotateFileWriter) sync() error {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.file == nil {
+		return nil
+	}
+	return fw.file.Sync()
+}
+
 func (fw *rotateFileWriter) Close() error {
+	return errors.Join(fw.close(), fw.eachLevelFile((*rotateFileWriter).Close))
+}
+
+func (fw *rotateFileWriter) close() error {
 	fw.mu.Lock()
 	defer fw.mu.Unlock()
 	if fw.done != nil {
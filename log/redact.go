@@ -0,0 +1,187 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// redactRule 脱敏规则，mask处理匹配到的内容
+type redactRule struct {
+	re     *regexp.Regexp
+	mask   func(s string) string
+	digits bool // 匹配内容前后不能是数字，用于手机号、证件号等纯数字的规则，前后可以是字母
+}
+
+var (
+	redactRulesMu sync.RWMutex
+	redactRules   = map[string]redactRule{
+		// 手机号，如 138****1234
+		"mobile": {
+			re:     regexp.MustCompile(`1[3-9]\d{9}`),
+			mask:   func(s string) string { return MaskMiddle(s, 3, 4) },
+			digits: true,
+		},
+		// 18位身份证号，如 110101********123X
+		"idcard": {
+			re:     regexp.MustCompile(`[1-9]\d{5}(?:18|19|20)\d{2}(?:0[1-9]|1[0-2])(?:0[1-9]|[12]\d|3[01])\d{3}[\dXx]`),
+			mask:   func(s string) string { return MaskMiddle(s, 6, 4) },
+			digits: true,
+		},
+		// 16到19位银行卡号，如 6222***********1234
+		"bankcard": {
+			re:     regexp.MustCompile(`[1-9]\d{15,18}`),
+			mask:   func(s string) string { return MaskMiddle(s, 4, 4) },
+			digits: true,
+		},
+		// 邮箱，保留用户名首字符和域名，如 z***@example.com
+		"email": {
+			re: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+			mask: func(s string) string {
+				name, domain, _ := strings.Cut(s, "@")
+				return MaskMiddle(name, 1, 0) + "@" + domain
+			},
+		},
+	}
+)
+
+// redactedValue 按字段名脱敏时替换字段值
+const redactedValue = "******"
+
+// RegisterRedactRule 注册脱敏规则，注册后可以在 Option.RedactRules 中使用name选择。
+// mask为nil时匹配内容整体替换为 ******，例如隐藏订单号中间部分：
+//
+//	log.RegisterRedactRule("order", regexp.MustCompile(`\bNO\d{12}\b`), func(s string) string {
+//		return log.MaskMiddle(s, 4, 2)
+//	})
+func RegisterRedactRule(name string, re *regexp.Regexp, mask func(s string) string) {
+	if mask == nil {
+		mask = func(string) string { return redactedValue }
+	}
+	redactRulesMu.Lock()
+	defer redactRulesMu.Unlock()
+	redactRules[strings.ToLower(name)] = redactRule{re: re, mask: mask}
+}
+
+// MaskMiddle 保留开头head个和末尾tail个字符，其余替换为*，字符数不足时全部替换
+func MaskMiddle(s string, head, tail int) string {
+	r := []rune(s)
+	if len(r) <= head+tail {
+		return strings.Repeat("*", len(r))
+	}
+	return string(r[:head]) + strings.Repeat("*", len(r)-head-tail) + string(r[len(r)-tail:])
+}
+
+// redactor 在日志写入输出前对内容和字段脱敏
+type redactor struct {
+	keys  []string // 需要脱敏的字段名，小写，字段名包含其中之一时整体替换
+	rules []redactRule
+}
+
+// newRedactor 解析脱敏配置，keys和rules均为逗号分隔，都为空时返回nil
+func newRedactor(keys, rules string) (*redactor, error) {
+	r := &redactor{}
+	for _, key := range strings.Split(keys, ",") {
+		if key = strings.ToLower(strings.TrimSpace(key)); key != "" {
+			r.keys = append(r.keys, key)
+		}
+	}
+
+	var errs []error
+	redactRulesMu.RLock()
+	for _, name := range strings.Split(rules, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		rule, ok := redactRules[name]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unrecognized redact rule: %q", name))
+			continue
+		}
+		r.rules = append(r.rules, rule)
+	}
+	redactRulesMu.RUnlock()
+
+	if len(r.keys) == 0 && len(r.rules) == 0 {
+		return nil, errors.Join(errs...)
+	}
+	return r, errors.Join(errs...)
+}
+
+// redact 对日志内容和字段脱敏。字段可能与子日志对象共享，有修改时复制一份
+func (r *redactor) redact(e *Entry) {
+	e.Message = r.redactString(e.Message)
+
+	copied := false
+	for i, f := range e.Fields {
+		v, changed := r.redactField(f)
+		if !changed {
+			continue
+		}
+		if !copied {
+			e.Fields = append([]Field(nil), e.Fields...)
+			copied = true
+		}
+		e.Fields[i].Value = v
+	}
+}
+
+func (r *redactor) redactField(f Field) (any, bool) {
+	key := strings.ToLower(f.Key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return redactedValue, true
+		}
+	}
+	if len(r.rules) == 0 || f.Value == nil {
+		return nil, false
+	}
+
+	// 字符串之外的值按输出时的格式检查，如整数形式的手机号
+	switch f.Value.(type) {
+	case bool, float32, float64:
+		return nil, false
+	}
+	s := stringify(f.Value)
+	if masked := r.redactString(s); masked != s {
+		return masked, true
+	}
+	return nil, false
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, rule := range r.rules {
+		s = rule.apply(s)
+	}
+	return s
+}
+
+func (rule redactRule) apply(s string) string {
+	locs := rule.re.FindAllStringIndex(s, -1)
+	if locs == nil {
+		return s
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range locs {
+		if rule.digits && (loc[0] > 0 && isDigit(s[loc[0]-1]) || loc[1] < len(s) && isDigit(s[loc[1]])) {
+			continue
+		}
+		b.WriteString(s[last:loc[0]])
+		b.WriteString(rule.mask(s[loc[0]:loc[1]]))
+		last = loc[1]
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,128 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultSampleInterval 采样周期缺省值
+var defaultSampleInterval = time.Second
+
+type samplerConfig struct {
+	Initial    int           // 每个周期内前Initial条全部输出
+	Thereafter int           // 之后每Thereafter条输出1条，为0时全部丢弃
+	Interval   time.Duration // 采样周期
+}
+
+// sampleKey 相同等级、tag、模板的日志视为重复日志
+type sampleKey struct {
+	level    Level
+	tag      string
+	template string
+}
+
+type sampleCounter struct {
+	n          uint64
+	suppressed uint64
+}
+
+// sampler 重复日志采样，每个周期结束时通过report输出被丢弃日志的统计
+type sampler struct {
+	cfg    samplerConfig
+	report func(key sampleKey, suppressed uint64)
+
+	mu       sync.Mutex
+	counters map[sampleKey]*sampleCounter
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+func newSampler(cfg samplerConfig, report func(key sampleKey, suppressed uint64)) *sampler {
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSampleInterval
+	}
+	s := &sampler{
+		cfg:      cfg,
+		report:   report,
+		counters: make(map[sampleKey]*sampleCounter),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// allow 判断该日志是否输出
+func (s *sampler) allow(key sampleKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.counters[key]
+	if !ok {
+		c = &sampleCounter{}
+		s.counters[key] = c
+	}
+	c.n++
+	if c.n <= uint64(s.cfg.Initial) {
+		return true
+	}
+	if s.cfg.Thereafter > 0 && (c.n-uint64(s.cfg.Initial))%uint64(s.cfg.Thereafter) == 0 {
+		return true
+	}
+	c.suppressed++
+	return false
+}
+
+func (s *sampler) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.reset()
+		case <-s.done:
+			s.reset()
+			return
+		}
+	}
+}
+
+// reset 开始新的采样周期，输出上个周期被丢弃日志的统计
+func (s *sampler) reset() {
+	s.mu.Lock()
+	counters := s.counters
+	s.counters = make(map[sampleKey]*sampleCounter)
+	s.mu.Unlock()
+
+	for key, c := range counters {
+		if c.suppressed > 0 {
+			s.report(key, c.suppressed)
+		}
+	}
+}
+
+// stop 停止采样，并输出当前周期的统计
+func (s *sampler) stop() {
+	select {
+	case <-s.done:
+	default:
+		close(s.done)
+	}
+	<-s.stopped
+}
+
+// reportSampled 输出采样丢弃日志的统计，与被丢弃的日志使用相同的等级和tag
+func (l *Logger) reportSampled(key sampleKey, suppressed uint64) {
+	l.write(&Entry{
+		Time:    time.Now(),
+		Level:   key.level,
+		Tag:     key.tag,
+		Message: fmt.Sprintf("%d similar log entries suppressed by sampler", suppressed),
+		Fields: []Field{
+			{Key: "template", Value: key.template},
+			{Key: "suppressed", Value: suppressed},
+		},
+	})
+}
@@ -0,0 +1,36 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+// ReopenOnSignal 收到信号时重新打开所有文件输出，缺省监听SIGHUP和SIGUSR1（Windows下仅SIGHUP）。
+// 返回的函数用于停止监听
+func (l *Logger) ReopenOnSignal(sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		sig = defaultReopenSignals
+	}
+
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig...)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				if err := l.Reopen(); err != nil {
+					_, _ = fmt.Fprintf(os.Stderr, "log: reopen log file error: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package log
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultReopenSignals = []os.Signal{syscall.SIGHUP}
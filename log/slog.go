@@ -0,0 +1,129 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+var _ slog.Handler = (*slogHandler)(nil)
+
+// slogHandler 将 log/slog 的日志输出到 Logger 配置的输出中
+type slogHandler struct {
+	l      *Logger
+	fields []Field // WithAttrs 预先附带的字段
+	prefix string  // WithGroup 产生的字段前缀，如 "g1.g2."
+}
+
+// NewSlogHandler 新建一个由 Logger 输出的 slog.Handler，
+// slog的组以 "group.key" 的形式平铺为字段
+func NewSlogHandler(l *Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// Slog 返回一个与当前日志对象共享输出配置的 *slog.Logger
+func (l *Logger) Slog() *slog.Logger {
+	return slog.New(NewSlogHandler(l))
+}
+
+// levelFromSlog 将slog的日志等级映射为Level
+func levelFromSlog(level slog.Level) Level {
+	switch {
+	case level < slog.LevelDebug:
+		return LevelTrace
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelInfo
+	case level < slog.LevelError:
+		return LevelWarn
+	default:
+		return LevelError
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Level().Enabled(levelFromSlog(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	level := levelFromSlog(r.Level)
+	// 与logw相同经过check，重复的日志同样被采样
+	if !h.l.check(level, "", r.Message) {
+		return nil
+	}
+
+	e := &Entry{
+		Time:    r.Time,
+		Level:   level,
+		Message: r.Message,
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	callerEnabled, callerMode, stackLevel := h.l.callerConfig()
+	if callerEnabled && r.PC != 0 {
+		e.Caller = callerFromPC(r.PC, callerMode)
+	}
+	if stackLevel != 0 && e.Level >= stackLevel {
+		e.Stack = captureStack()
+	}
+
+	fields := make([]Field, 0, len(h.l.fields)+len(h.fields)+r.NumAttrs())
+	fields = append(fields, h.l.fields...)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, h.prefix, a)
+		return true
+	})
+	e.Fields = append(fields, contextFields(ctx)...)
+
+	h.l.write(e)
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	clone := *h
+	clone.fields = make([]Field, 0, len(h.fields)+len(attrs))
+	clone.fields = append(clone.fields, h.fields...)
+	for _, a := range attrs {
+		clone.fields = appendSlogAttr(clone.fields, h.prefix, a)
+	}
+	return &clone
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// appendSlogAttr 将slog的属性转换为字段，组属性展开为 "group.key"
+func appendSlogAttr(fields []Field, prefix string, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key != "" {
+			prefix = prefix + a.Key + "."
+		}
+		for _, ga := range attrs {
+			fields = appendSlogAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, Field{Key: prefix + a.Key, Value: a.Value.Any()})
+}
@@ -0,0 +1,49 @@
+package log
+
+import (
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// maxStackDepth 调用栈最多记录的层数
+const maxStackDepth = 64
+
+// logPackagePrefix 本包函数名前缀，如 "github.com/zngw/golib/log."，记录调用栈时跳过本包的调用
+var logPackagePrefix = reflect.TypeOf(Logger{}).PkgPath() + "."
+
+// stackSkipPrefixes 记录调用栈时跳过的函数名前缀
+var stackSkipPrefixes = []string{
+	logPackagePrefix,
+	"runtime.",
+	"log/slog.",
+}
+
+// captureStack 记录当前协程的调用栈，每层格式为 "function file:line"，跳过runtime和本包的调用
+func captureStack() []string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []string
+	for {
+		frame, more := frames.Next()
+		if !skipStackFrame(frame.Function) {
+			stack = append(stack, frame.Function+" "+frame.File+":"+strconv.Itoa(frame.Line))
+		}
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+func skipStackFrame(function string) bool {
+	for _, prefix := range stackSkipPrefixes {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
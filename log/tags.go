@@ -1,37 +1,70 @@
 package log
 
-import "strings"
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
 
-type tags map[string]bool
+// tags Tag过滤，值为该Tag的最低输出等级，为0时使用全局等级
+type tags map[string]Level
 
-func (t *tags) GetTag(tag string) (msg string, show bool) {
+// Enabled 判断带tag的level等级日志是否允许输出。
+// 不带tag的日志不受tag过滤影响，与未配置等级的tag一样使用minLevel判断
+func (t *tags) Enabled(tag string, level, minLevel Level) bool {
 	if tag == "" {
-		show = true
-		return
+		return minLevel.Enabled(level)
 	}
 
 	if len(*t) > 0 {
-		if _, ok := (*t)[tag]; !ok {
-			return
+		tagLevel, ok := (*t)[tag]
+		if !ok {
+			return false
+		}
+		if tagLevel != 0 {
+			return tagLevel.Enabled(level)
 		}
 	}
 
-	msg = "[Tag:" + tag + "] "
-	show = true
+	return minLevel.Enabled(level)
+}
 
-	return
+// minLevel 返回所有tag中配置的最低等级，没有配置等级时返回0
+func (t *tags) minLevel() Level {
+	var min Level
+	for _, level := range *t {
+		if level != 0 && (min == 0 || level < min) {
+			min = level
+		}
+	}
+	return min
 }
 
-func parseTags(str string) (ts tags) {
+// parseTags 解析tag配置，格式为 "db:debug,net:warn,cache"，不带等级的tag使用全局等级。
+// 等级无法识别时仍保留该tag并返回错误
+func parseTags(str string) (ts tags, err error) {
 	if len(str) == 0 {
-		return tags{}
+		return tags{}, nil
 	}
 
+	var errs []error
 	ts = make(tags)
 	arr := strings.Split(str, ",")
 	for _, v := range arr {
-		ts[v] = true
+		name, levelText, hasLevel := strings.Cut(strings.TrimSpace(v), ":")
+		if name == "" {
+			continue
+		}
+
+		var level Level
+		if hasLevel {
+			if err := level.UnmarshalText([]byte(levelText)); err != nil {
+				errs = append(errs, fmt.Errorf("tag %q: %w", name, err))
+				level = 0
+			}
+		}
+		ts[name] = level
 	}
 
-	return
+	return ts, errors.Join(errs...)
 }
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+// WatchConfig 加载配置文件并定期检查文件是否修改，修改后重新加载。格式按扩展名识别，同LoadOptionFromFile。
+// 重新加载时只更新变化的配置，等级、Tag等直接生效，输出配置未变化时不会重新打开输出，
+// 配置文件中没有设置输出时保留原有输出，Writer等无法在文件中设置的配置保持不变；
+// 新配置有错误时保留原配置并将错误输出到标准错误。interval为检查间隔，缺省为2秒。
+// 首次加载失败时返回错误，返回的函数用于停止监听，Close时也会停止
+func (l *Logger) WatchConfig(path string, interval time.Duration) (stop func(), err error) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	opt, err := LoadOptionFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if prev := l.swapStopFunc(&l.stopWatchConfig, nil); prev != nil {
+		prev()
+	}
+	l.WithOptions(opt)
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		l.watchConfig(path, interval, opt, info, done)
+	}()
+
+	// 等待正在进行的重新加载完成，避免与Close同时修改输出
+	var once sync.Once
+	stop = func() {
+		once.Do(func() { close(done) })
+		<-exited
+	}
+	// 同时调用WatchConfig时只保留最后一个监听
+	if prev := l.swapStopFunc(&l.stopWatchConfig, stop); prev != nil {
+		prev()
+	}
+	return stop, nil
+}
+
+func (l *Logger) watchConfig(path string, interval time.Duration, opt Option, last os.FileInfo, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-done:
+			return
+		}
+
+		// 编辑器保存时文件可能短暂不存在，下次检查时再处理
+		info, err := os.Stat(path)
+		if err != nil || (info.ModTime().Equal(last.ModTime()) && info.Size() == last.Size()) {
+			continue
+		}
+		last = info
+
+		newOpt, err := LoadOptionFromFile(path)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "log: reload config error: %v\n", err)
+			continue
+		}
+		l.applyOptions(newOpt, &opt)
+		opt = newOpt
+	}
+}
@@ -176,10 +176,16 @@ func (r *RingBuffer[T]) Reset() {
 
 	lastCell.w = 0
 	lastCell.r = 0
+	lastCell.fullFlag = false
 	r.readCell.r = 0
 	r.readCell.w = 0
+	r.readCell.fullFlag = false
 	r.cellCount = 2
 	r.count.Store(0)
 
+	// 前后指针都要重新连接，否则扩容时会插入到已移除的cell后面
 	lastCell.next = r.readCell
+	lastCell.pre = r.readCell
+	r.readCell.pre = lastCell
+	r.writeCell = r.readCell
 }